@@ -0,0 +1,282 @@
+// Package client is the Go client of the Rule API.
+//
+//	c := client.New("http://localhost:8081/rule/v1", client.WithApiKey("key"),
+//		client.WithVerdictCache(10000, time.Minute))
+//	verdict, err := c.CheckCrawl(ctx, "https://example.com/page", "my-bot", "")
+//
+// The requests are retried with an exponential backoff on network errors and on 502, 503 and 504 responses without
+// a verdict, except for the requests that create or delete resources. The errors of the API are returned as *Error.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/IliaW/rule-api/internal/model"
+)
+
+// The types of the requests and the responses of the API.
+type (
+	AllowedCrawlResponse   = model.AllowedCrawlResponse
+	TokenVerdict           = model.TokenVerdict
+	AiTxtVerdict           = model.AiTxtVerdict
+	RobotsContent          = model.RobotsContent
+	CrawlStreamRecord      = model.CrawlStreamRecord
+	CrawlStreamResult      = model.CrawlStreamResult
+	Rule                   = model.Rule
+	RulePage               = model.RulePage
+	DomainAlias            = model.DomainAlias
+	RuleComparisonRequest  = model.RuleComparisonRequest
+	RuleComparisonResponse = model.RuleComparisonResponse
+	VerdictCompare         = model.VerdictCompare
+	TdmReservationResponse = model.TdmReservationResponse
+	PageDirectivesResponse = model.PageDirectivesResponse
+	Job                    = model.Job
+	ErrorResponse          = model.ErrorResponse
+)
+
+const (
+	apiKeyHeader      = "X-API-Key"
+	maxPeekSize       = 64 * 1024
+	defaultMaxRetries = 2
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 2 * time.Second
+	defaultTimeout    = 30 * time.Second
+)
+
+// Error is the error response of the API.
+type Error struct {
+	StatusCode int
+	ErrorResponse
+}
+
+func (e *Error) Error() string {
+	message := fmt.Sprintf("rule-api: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	if e.Details != "" {
+		message += ". " + e.Details
+	}
+	return message
+}
+
+type Client struct {
+	baseUrl    string
+	apiKey     string
+	httpClient *http.Client
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	verdicts   *verdictCache
+}
+
+type Option func(*Client)
+
+// WithApiKey sets the X-API-Key header. The key is required by the custom rule, domain alias and job endpoints.
+func WithApiKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithHttpClient replaces the default http client with a 30s timeout.
+func WithHttpClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times a request is retried and the bounds of the exponential backoff between
+// the attempts. 0 retries disables them. The default is 2 retries with the backoff from 100ms to 2s.
+func WithRetries(maxRetries int, minBackoff time.Duration, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithVerdictCache keeps up to size verdicts of CheckCrawl in memory for the ttl, or until the robots.txt file
+// expires in the cache of the server. The verdicts with an error are not kept.
+func WithVerdictCache(size int, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.verdicts = newVerdictCache(size, ttl)
+	}
+}
+
+// New creates the client for the API at baseUrl, e.g. 'http://localhost:8081/rule/v1'.
+func New(baseUrl string, opts ...Option) *Client {
+	c := &Client{
+		baseUrl:    strings.TrimSuffix(baseUrl, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Ping checks that the server is up. The /ping endpoint is served at the root of the server.
+func (c *Client) Ping(ctx context.Context) error {
+	baseUrl, err := url.Parse(c.baseUrl)
+	if err != nil {
+		return err
+	}
+	return c.doJSON(ctx, request{
+		method:  http.MethodGet,
+		fullUrl: baseUrl.ResolveReference(&url.URL{Path: "/ping"}).String(),
+		retry:   true,
+	}, nil)
+}
+
+type request struct {
+	method string
+	path   string
+	// fullUrl is used instead of the base url and the path if set
+	fullUrl     string
+	query       url.Values
+	body        []byte
+	contentType string
+	// stream is the body that can not be sent again. The request with the stream is not retried
+	stream io.Reader
+	retry  bool
+}
+
+// send sends the request and retries it if shouldRetry allows it. The last response is returned as is, the caller
+// closes its body.
+func (c *Client) send(ctx context.Context, req request) (*http.Response, error) {
+	requestUrl := req.fullUrl
+	if requestUrl == "" {
+		requestUrl = c.baseUrl + req.path
+	}
+	if len(req.query) != 0 {
+		requestUrl += "?" + req.query.Encode()
+	}
+	maxRetries := 0
+	if req.retry && req.stream == nil {
+		maxRetries = c.maxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		body := req.stream
+		if body == nil && req.body != nil {
+			body = bytes.NewReader(req.body)
+		}
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, requestUrl, body)
+		if err != nil {
+			return nil, err
+		}
+		if req.contentType != "" {
+			httpReq.Header.Set("Content-Type", req.contentType)
+		}
+		if c.apiKey != "" {
+			httpReq.Header.Set(apiKeyHeader, c.apiKey)
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if attempt >= maxRetries || ctx.Err() != nil || !shouldRetry(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.backoff(attempt)):
+		}
+	}
+}
+
+// shouldRetry reports whether the request is retried: on network errors and on 502, 503 and 504 responses, e.g. of
+// a proxy or of an overloaded server. The other 5xx responses are not retried, since the request may have been
+// applied. A verdict with these statuses is the fetch error of the target, so the check is not retried either.
+// The start of the body is read to find the verdict, the body of the response is still readable in full.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return false
+	}
+	start, err := io.ReadAll(io.LimitReader(resp.Body, maxPeekSize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(start), resp.Body), resp.Body}
+	return err != nil || !isVerdict(start)
+}
+
+// backoff returns the exponential backoff with a jitter for the attempt.
+func (c *Client) backoff(attempt int) time.Duration {
+	backoff := c.minBackoff << attempt
+	if backoff > c.maxBackoff || backoff <= 0 {
+		backoff = c.maxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// doJSON sends the request and decodes the json response into out. The non-2xx responses are returned as *Error.
+func (c *Client) doJSON(ctx context.Context, req request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return decodeError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode the response. %w", err)
+	}
+	return nil
+}
+
+// decodeError reads the model.ErrorResponse from the response. If the body is not an error response, e.g.
+// from a proxy, the status text is the message.
+func decodeError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	if err := json.Unmarshal(body, &apiErr.ErrorResponse); err != nil || apiErr.Code == "" {
+		apiErr.ErrorResponse = ErrorResponse{Code: http.StatusText(resp.StatusCode), Message: string(body)}
+	}
+	return apiErr
+}
+
+func jsonBody(v any) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the request. %w", err)
+	}
+	return body, nil
+}
+
+func closeBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+// IsNotFound reports whether the error is the 404 response of the API.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
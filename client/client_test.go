@@ -0,0 +1,359 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestServer serves the handler and counts the requests.
+func newTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newTestClient(server *httptest.Server, opts ...Option) *Client {
+	opts = append([]Option{WithRetries(2, time.Millisecond, 5*time.Millisecond)}, opts...)
+	return New(server.URL+"/rule/v1", opts...)
+}
+
+func Test_CheckCrawl(t *testing.T) {
+	testSet := []struct {
+		name             string
+		responses        []string
+		statusCodes      []int
+		expectedVerdict  string
+		expectedError    string
+		expectedRequests int32
+	}{
+		{
+			name:             "allowed",
+			responses:        []string{"{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"}"},
+			statusCodes:      []int{http.StatusOK},
+			expectedVerdict:  "{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"}",
+			expectedRequests: 1,
+		},
+		{
+			name: "retried on 5xx",
+			responses: []string{"upstream error", "upstream error",
+				"{\"is_allowed\":false,\"blocked\":true,\"status_code\":200,\"error\":\"\"}"},
+			statusCodes:      []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			expectedVerdict:  "{\"is_allowed\":false,\"blocked\":true,\"status_code\":200,\"error\":\"\"}",
+			expectedRequests: 3,
+		},
+		{
+			name: "failed check verdict is not retried",
+			responses: []string{"{\"is_allowed\":false,\"blocked\":false,\"status_code\":503," +
+				"\"error\":\"the robots.txt file returned 503\",\"error_code\":\"ROBOTS_5XX\"}"},
+			statusCodes: []int{http.StatusServiceUnavailable},
+			expectedVerdict: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":503," +
+				"\"error\":\"the robots.txt file returned 503\",\"error_code\":\"ROBOTS_5XX\"}",
+			expectedRequests: 1,
+		},
+		{
+			name:             "internal error is not retried",
+			responses:        []string{"{\"code\":\"INTERNAL_ERROR\",\"message\":\"internal error\"}"},
+			statusCodes:      []int{http.StatusInternalServerError},
+			expectedError:    "rule-api: 500 INTERNAL_ERROR: internal error",
+			expectedRequests: 1,
+		},
+		{
+			name: "bad request verdict is not retried",
+			responses: []string{"{\"is_allowed\":false,\"blocked\":false,\"status_code\":400," +
+				"\"error\":\"'user_agent' query parameter is required\",\"error_code\":\"INVALID_REQUEST\"}"},
+			statusCodes: []int{http.StatusBadRequest},
+			expectedVerdict: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":400," +
+				"\"error\":\"'user_agent' query parameter is required\",\"error_code\":\"INVALID_REQUEST\"}",
+			expectedRequests: 1,
+		},
+		{
+			name:             "error response",
+			responses:        []string{"{\"code\":\"NOT_FOUND\",\"message\":\"no route found\"}"},
+			statusCodes:      []int{http.StatusNotFound},
+			expectedError:    "rule-api: 404 NOT_FOUND: no route found",
+			expectedRequests: 1,
+		},
+		{
+			name:             "not json error response",
+			responses:        []string{"bad gateway"},
+			statusCodes:      []int{http.StatusBadGateway},
+			expectedError:    "rule-api: 502 Bad Gateway: bad gateway",
+			expectedRequests: 3,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			var attempt atomic.Int32
+			server, requests := newTestServer(tt, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(tt, "/rule/v1/crawl-allowed", r.URL.Path)
+				assert.Equal(tt, "url=https%3A%2F%2Fexample.com%2Fpage&user_agent=bot", r.URL.RawQuery)
+				i := min(int(attempt.Add(1))-1, len(test.responses)-1)
+				w.WriteHeader(test.statusCodes[i])
+				_, _ = w.Write([]byte(test.responses[i]))
+			})
+
+			verdict, err := newTestClient(server).CheckCrawl(context.Background(), "https://example.com/page", "bot",
+				"")
+
+			if test.expectedError != "" {
+				assert.EqualError(tt, err, test.expectedError)
+				assert.Nil(tt, verdict)
+			} else {
+				require.NoError(tt, err)
+				verdictJson, _ := json.Marshal(verdict)
+				assert.Equal(tt, test.expectedVerdict, string(verdictJson))
+			}
+			assert.Equal(tt, test.expectedRequests, requests.Load())
+		})
+	}
+}
+
+func Test_CheckCrawl_VerdictCache(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("url") {
+		case "https://failed.com/":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("{\"is_allowed\":false,\"blocked\":false,\"status_code\":403," +
+				"\"error\":\"the target address is not allowed\",\"error_code\":\"FORBIDDEN_ADDRESS\"}"))
+		case "https://expiring.com/":
+			_, _ = w.Write([]byte("{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"expires_at\":\"2025-01-01T12:00:10Z\"}"))
+		default:
+			_, _ = w.Write([]byte("{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"}"))
+		}
+	})
+	c := newTestClient(server, WithVerdictCache(2, time.Minute))
+	now := testTime
+	c.verdicts.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for _, url := range []string{"https://example.com/", "https://example.com/", "https://failed.com/",
+		"https://failed.com/", "https://expiring.com/", "https://expiring.com/"} {
+		_, err := c.CheckCrawl(ctx, url, "bot", "")
+		require.NoError(t, err)
+	}
+	// the verdicts with an error are not cached
+	assert.Equal(t, int32(4), requests.Load())
+
+	// the verdict expires with the robots.txt file in the cache of the server
+	now = testTime.Add(20 * time.Second)
+	_, _ = c.CheckCrawl(ctx, "https://expiring.com/", "bot", "")
+	_, _ = c.CheckCrawl(ctx, "https://example.com/", "bot", "")
+	assert.Equal(t, int32(5), requests.Load())
+
+	// the least recently used verdict is evicted
+	_, _ = c.CheckCrawl(ctx, "https://other.com/", "bot", "")
+	_, _ = c.CheckCrawl(ctx, "https://expiring.com/", "bot", "")
+	assert.Equal(t, int32(7), requests.Load())
+}
+
+func Test_CustomRule(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("{\"code\":\"UNAUTHORIZED\",\"message\":\"invalid api-key\"}"))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /rule/v1/custom-rule":
+			if r.URL.Query().Get("url") == "https://duplicate.com" {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte("{\"code\":\"CONFLICT\",\"message\":\"failed to save custom rule\"," +
+					"\"details\":\"rule with domain 'duplicate.com' already exists\"}"))
+				return
+			}
+			assert.Equal(t, "blocked=true&url=https%3A%2F%2Fexample.com", r.URL.RawQuery)
+			assert.Equal(t, "User-agent: *\nDisallow: /", string(body))
+			_, _ = w.Write([]byte("{\"id\":7}"))
+		case "PUT /rule/v1/custom-rule":
+			assert.Equal(t, "blocked=false&id=7", r.URL.RawQuery)
+			_, _ = fmt.Fprintf(w, "{\"id\":7,\"domain\":\"example.com\",\"blocked\":false,\"robots_txt\":%q,"+
+				"\"created_at\":\"2025-01-01T12:00:00Z\",\"updated_at\":\"2025-01-01T12:00:00Z\"}", body)
+		case "DELETE /rule/v1/custom-rule":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("{\"code\":\"INTERNAL_ERROR\",\"message\":\"failed to delete custom rule\"}"))
+		}
+	})
+	ctx := context.Background()
+	c := newTestClient(server, WithApiKey("key"))
+
+	id, err := c.CreateCustomRule(ctx, "https://example.com", "User-agent: *\nDisallow: /", true)
+	require.NoError(t, err)
+	assert.Equal(t, 7, id)
+
+	rule, err := c.UpdateCustomRule(ctx, id, "User-agent: *\nAllow: /", false)
+	require.NoError(t, err)
+	assert.Equal(t, Rule{ID: 7, Domain: "example.com", RobotsTxt: "User-agent: *\nAllow: /", CreatedAt: testTime,
+		UpdatedAt: testTime}, *rule)
+
+	_, err = c.CreateCustomRule(ctx, "https://duplicate.com", "User-agent: *", false)
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+	assert.Equal(t, "CONFLICT", apiErr.Code)
+	assert.Equal(t, "rule with domain 'duplicate.com' already exists", apiErr.Details)
+
+	requests.Store(0)
+	err = c.DeleteCustomRule(ctx, id)
+	assert.EqualError(t, err, "rule-api: 500 INTERNAL_ERROR: failed to delete custom rule")
+	assert.Equal(t, int32(1), requests.Load())
+
+	_, err = newTestClient(server).GetCustomRule(ctx, 7)
+	assert.EqualError(t, err, "rule-api: 401 UNAUTHORIZED: invalid api-key")
+}
+
+func Test_ListCustomRules(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rule/v1/custom-rules", r.URL.Path)
+		if r.URL.Query().Get("cursor") == "" {
+			assert.Equal(t, "blocked=false&domain=example&limit=1&sort=domain&updated_from=2025-01-01T12%3A00%3A00Z",
+				r.URL.RawQuery)
+			_, _ = w.Write([]byte("{\"rules\":[{\"id\":1,\"domain\":\"a.example.com\"}],\"next_cursor\":\"next\"}"))
+			return
+		}
+		_, _ = w.Write([]byte("{\"rules\":[{\"id\":2,\"domain\":\"b.example.com\"}]}"))
+	})
+	ctx := context.Background()
+	c := newTestClient(server)
+
+	blocked := false
+	opts := ListRulesOptions{Domain: "example", Blocked: &blocked, UpdatedFrom: testTime, Sort: "domain", Limit: 1}
+	page, err := c.ListCustomRules(ctx, opts)
+	require.NoError(t, err)
+	assert.Equal(t, RulePage{Rules: []*Rule{{ID: 1, Domain: "a.example.com"}}, NextCursor: "next"}, *page)
+
+	opts.Cursor = page.NextCursor
+	page, err = c.ListCustomRules(ctx, opts)
+	require.NoError(t, err)
+	assert.Equal(t, RulePage{Rules: []*Rule{{ID: 2, Domain: "b.example.com"}}}, *page)
+}
+
+func Test_CreateCustomRule_NotRetried(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("{\"code\":\"INTERNAL_ERROR\",\"message\":\"failed to save custom rule\"}"))
+	})
+
+	_, err := newTestClient(server).CreateCustomRule(context.Background(), "https://example.com", "User-agent: *",
+		false)
+
+	assert.EqualError(t, err, "rule-api: 500 INTERNAL_ERROR: failed to save custom rule")
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func Test_DeleteCustomRule_NotRetried(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("service unavailable"))
+	})
+
+	// the delete may have been applied, the retry would get 404
+	err := newTestClient(server).DeleteCustomRule(context.Background(), 1)
+
+	assert.EqualError(t, err, "rule-api: 503 Service Unavailable: service unavailable")
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func Test_CheckCrawlStream(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		scanner := bufio.NewScanner(r.Body)
+		encoder := json.NewEncoder(w)
+		for i := 0; scanner.Scan(); i++ {
+			var record CrawlStreamRecord
+			_ = json.Unmarshal(scanner.Bytes(), &record)
+			_ = encoder.Encode(CrawlStreamResult{Index: i, Url: record.Url, UserAgent: record.UserAgent,
+				AllowedCrawlResponse: AllowedCrawlResponse{IsAllowed: true, StatusCode: http.StatusOK}})
+		}
+	})
+
+	results := make([]string, 0)
+	err := newTestClient(server).CheckCrawlStream(context.Background(), []CrawlStreamRecord{
+		{Url: "https://example.com/a", UserAgent: "bot"},
+		{Url: "https://example.com/b", UserAgent: "bot"},
+	}, func(result CrawlStreamResult) error {
+		results = append(results, fmt.Sprintf("%d %s %t", result.Index, result.Url, result.IsAllowed))
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0 https://example.com/a true", "1 https://example.com/b true"}, results)
+}
+
+func Test_Job(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /rule/v1/jobs":
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, "user_agent=bot", r.URL.RawQuery)
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = fmt.Fprintf(w, "{\"id\":\"job-1\",\"status\":\"queued\",\"user_agent\":\"bot\",\"total\":%d,"+
+				"\"processed\":0,\"allowed\":0,\"disallowed\":0,\"failed\":0,\"created_at\":\"2025-01-01T12:00:00Z\"}",
+				strings.Count(string(body), "\n"))
+		case "GET /rule/v1/jobs/job-1/result":
+			_, _ = w.Write([]byte("{\"index\":0,\"url\":\"https://example.com/a\",\"user_agent\":\"bot\"," +
+				"\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"}\n" +
+				"{\"index\":1,\"url\":\"https://example.com/b\",\"user_agent\":\"bot\"," +
+				"\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\"}\n"))
+		case "GET /rule/v1/jobs/job-2/result":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte("{\"code\":\"CONFLICT\",\"message\":\"the job is running\"}"))
+		}
+	})
+	ctx := context.Background()
+	c := newTestClient(server)
+
+	job, err := c.CreateJob(ctx, strings.NewReader("https://example.com/a\nhttps://example.com/b\n"), "bot", "")
+	require.NoError(t, err)
+	assert.Equal(t, Job{ID: "job-1", Status: "queued", UserAgent: "bot", Total: 2, CreatedAt: testTime}, *job)
+
+	result, err := c.GetJobResult(ctx, job.ID)
+	require.NoError(t, err)
+	defer func() {
+		_ = result.Close()
+	}()
+	verdicts := make([]bool, 0)
+	err = JobResults(result, func(result CrawlStreamResult) error {
+		verdicts = append(verdicts, result.IsAllowed)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, verdicts)
+
+	_, err = c.GetJobResult(ctx, "job-2")
+	assert.EqualError(t, err, "rule-api: 409 CONFLICT: the job is running")
+}
+
+func Test_Client_ContextCancel(t *testing.T) {
+	server, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := newTestClient(server, WithRetries(10, time.Second, time.Second)).GetJob(ctx, "job-1")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), requests.Load())
+}
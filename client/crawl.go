@@ -0,0 +1,154 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CheckCrawl checks if the user agent is allowed to crawl the url. purpose is optional, e.g. 'ai-training'.
+// As in the API, the errors of the check itself, e.g. the robots.txt file could not be fetched, are returned in
+// the verdict as the status code and the error code. Only the other errors are returned as the error.
+func (c *Client) CheckCrawl(ctx context.Context, url string, userAgent string,
+	purpose string) (*AllowedCrawlResponse, error) {
+	key := verdictKey(url, userAgent, purpose)
+	if c.verdicts != nil {
+		if verdict, ok := c.verdicts.get(key); ok {
+			return &verdict, nil
+		}
+	}
+
+	var verdict AllowedCrawlResponse
+	query := urlQuery("url", url, "user_agent", userAgent, "purpose", purpose)
+	if err := c.doVerdict(ctx, request{method: http.MethodGet, path: "/crawl-allowed", query: query, retry: true},
+		&verdict); err != nil {
+		return nil, err
+	}
+
+	if c.verdicts != nil && verdict.ErrorCode == "" && verdict.Error == "" {
+		c.verdicts.add(key, verdict)
+	}
+	return &verdict, nil
+}
+
+// CheckCrawlStream sends the records to /crawl-allowed/stream and calls fn for each result as it arrives.
+// The results are unordered, 'Index' is the position of the record. The request is not retried.
+func (c *Client) CheckCrawlStream(ctx context.Context, records []CrawlStreamRecord,
+	fn func(CrawlStreamResult) error) error {
+	reader, writer := io.Pipe()
+	go func() {
+		encoder := json.NewEncoder(writer)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				_ = writer.CloseWithError(err)
+				return
+			}
+		}
+		_ = writer.Close()
+	}()
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	resp, err := c.send(ctx, request{
+		method:      http.MethodPost,
+		path:        "/crawl-allowed/stream",
+		stream:      reader,
+		contentType: "application/x-ndjson",
+	})
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+	if resp.StatusCode != http.StatusOK {
+		return decodeError(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var result CrawlStreamResult
+		if err = json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			return fmt.Errorf("failed to decode the result. %w", err)
+		}
+		if err = fn(result); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// GetTdmReservation resolves the Text and Data Mining reservation for the url. If checkHeaders is true,
+// the headers of the url are requested when tdmrep.json has no matching rule.
+func (c *Client) GetTdmReservation(ctx context.Context, url string,
+	checkHeaders bool) (*TdmReservationResponse, error) {
+	var resp TdmReservationResponse
+	query := urlQuery("url", url, "check_headers", strconv.FormatBool(checkHeaders))
+	if err := c.doVerdict(ctx, request{method: http.MethodGet, path: "/tdm-reservation", query: query, retry: true},
+		&resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetPageDirectives gets the page-level robots directives of the url for the user agent. If headersOnly is true,
+// only the X-Robots-Tag headers are checked.
+func (c *Client) GetPageDirectives(ctx context.Context, url string, userAgent string,
+	headersOnly bool) (*PageDirectivesResponse, error) {
+	var resp PageDirectivesResponse
+	query := urlQuery("url", url, "user_agent", userAgent, "headers_only", strconv.FormatBool(headersOnly))
+	if err := c.doVerdict(ctx, request{method: http.MethodGet, path: "/page-directives", query: query, retry: true},
+		&resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// doVerdict is doJSON for the endpoints that return the result of the check with a non-2xx status,
+// e.g. when the target is not reachable.
+func (c *Client) doVerdict(ctx context.Context, req request, out any) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the response. %w", err)
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices && !isVerdict(body) {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return decodeError(resp)
+	}
+	if err = json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode the response. %w", err)
+	}
+	return nil
+}
+
+// isVerdict reports whether the body is the result of a check rather than the error response.
+func isVerdict(body []byte) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["status_code"]
+	return ok
+}
+
+// urlQuery builds the query from the key and value pairs. The empty values are skipped.
+func urlQuery(pairs ...string) url.Values {
+	query := url.Values{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			query.Set(pairs[i], pairs[i+1])
+		}
+	}
+	return query
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// CreateJob uploads the url list, one url per line, to be checked in the background. The request is not retried.
+func (c *Client) CreateJob(ctx context.Context, urls io.Reader, userAgent string, purpose string) (*Job, error) {
+	var job Job
+	if err := c.doJSON(ctx, request{
+		method:      http.MethodPost,
+		path:        "/jobs",
+		query:       urlQuery("user_agent", userAgent, "purpose", purpose),
+		stream:      urls,
+		contentType: "text/plain",
+	}, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJob gets the status and the progress of the job.
+func (c *Client) GetJob(ctx context.Context, id string) (*Job, error) {
+	var job Job
+	if err := c.doJSON(ctx, request{method: http.MethodGet, path: "/jobs/" + url.PathEscape(id), retry: true},
+		&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJobResult downloads the NDJSON results of the completed job. The caller closes the reader.
+// Use JobResults to decode it.
+func (c *Client) GetJobResult(ctx context.Context, id string) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, request{
+		method: http.MethodGet,
+		path:   "/jobs/" + url.PathEscape(id) + "/result",
+		retry:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer closeBody(resp)
+		return nil, decodeError(resp)
+	}
+	return resp.Body, nil
+}
+
+// JobResults calls fn for each result of the downloaded job result, in the order of the url list.
+func JobResults(result io.Reader, fn func(CrawlStreamResult) error) error {
+	decoder := json.NewDecoder(result)
+	for {
+		var line CrawlStreamResult
+		err := decoder.Decode(&line)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode the result. %w", err)
+		}
+		if err = fn(line); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const defaultVerdictTtl = 5 * time.Minute
+
+// verdictCache is the LRU of the verdicts with the expiration time.
+type verdictCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	items map[string]*list.Element
+	order *list.List
+	now   func() time.Time
+}
+
+type verdictEntry struct {
+	key       string
+	verdict   AllowedCrawlResponse
+	expiresAt time.Time
+}
+
+func newVerdictCache(size int, ttl time.Duration) *verdictCache {
+	if ttl <= 0 {
+		ttl = defaultVerdictTtl
+	}
+	return &verdictCache{
+		size:  max(size, 1),
+		ttl:   ttl,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+		now:   time.Now,
+	}
+}
+
+func verdictKey(url string, userAgent string, purpose string) string {
+	return purpose + "\x00" + userAgent + "\x00" + url
+}
+
+func (c *verdictCache) get(key string) (AllowedCrawlResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.items[key]
+	if !ok {
+		return AllowedCrawlResponse{}, false
+	}
+	entry := element.Value.(*verdictEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.items, key)
+		return AllowedCrawlResponse{}, false
+	}
+	c.order.MoveToFront(element)
+	return entry.verdict, true
+}
+
+func (c *verdictCache) add(key string, verdict AllowedCrawlResponse) {
+	expiresAt := c.now().Add(c.ttl)
+	// the verdict is not kept longer than the robots.txt file in the cache of the server
+	if verdict.ExpiresAt != nil && verdict.ExpiresAt.Before(expiresAt) {
+		expiresAt = *verdict.ExpiresAt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.items[key]; ok {
+		element.Value = &verdictEntry{key: key, verdict: verdict, expiresAt: expiresAt}
+		c.order.MoveToFront(element)
+		return
+	}
+	c.items[key] = c.order.PushFront(&verdictEntry{key: key, verdict: verdict, expiresAt: expiresAt})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*verdictEntry).key)
+	}
+}
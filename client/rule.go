@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// GetCustomRule gets the custom rule by the id.
+func (c *Client) GetCustomRule(ctx context.Context, id int) (*Rule, error) {
+	return c.getCustomRule(ctx, urlQuery("id", strconv.Itoa(id)))
+}
+
+// GetCustomRuleByUrl gets the custom rule for the domain of the url.
+func (c *Client) GetCustomRuleByUrl(ctx context.Context, url string) (*Rule, error) {
+	return c.getCustomRule(ctx, urlQuery("url", url))
+}
+
+func (c *Client) getCustomRule(ctx context.Context, query url.Values) (*Rule, error) {
+	var rule Rule
+	if err := c.doJSON(ctx, request{method: http.MethodGet, path: "/custom-rule", query: query, retry: true},
+		&rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListRulesOptions are the filters, the sort and the page of ListCustomRules. The zero values are not sent.
+type ListRulesOptions struct {
+	// Domain and RobotsTxt are the case-insensitive substrings of the domain and the robots.txt content
+	Domain      string
+	RobotsTxt   string
+	Blocked     *bool
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	// Sort is 'updated_at' (default) or 'domain'. Order is 'asc' or 'desc'
+	Sort  string
+	Order string
+	Limit int
+	// Cursor is the NextCursor of the previous page
+	Cursor string
+}
+
+// ListCustomRules gets one page of the custom rules. Pass the NextCursor of the page as the Cursor to get the next
+// one, it is empty on the last page.
+func (c *Client) ListCustomRules(ctx context.Context, opts ListRulesOptions) (*RulePage, error) {
+	query := urlQuery("domain", opts.Domain, "robots_txt", opts.RobotsTxt, "sort", opts.Sort, "order", opts.Order,
+		"cursor", opts.Cursor, "created_from", formatTime(opts.CreatedFrom), "created_to", formatTime(opts.CreatedTo),
+		"updated_from", formatTime(opts.UpdatedFrom), "updated_to", formatTime(opts.UpdatedTo))
+	if opts.Blocked != nil {
+		query.Set("blocked", strconv.FormatBool(*opts.Blocked))
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	var page RulePage
+	if err := c.doJSON(ctx, request{method: http.MethodGet, path: "/custom-rules", query: query, retry: true},
+		&page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// CreateCustomRule creates the custom rule with the robots.txt content for the domain of the url.
+// Returns the id of the rule. The request is not retried.
+func (c *Client) CreateCustomRule(ctx context.Context, url string, robotsTxt string, blocked bool) (int, error) {
+	var resp struct {
+		ID int `json:"id"`
+	}
+	if err := c.doJSON(ctx, request{
+		method:      http.MethodPost,
+		path:        "/custom-rule",
+		query:       urlQuery("url", url, "blocked", strconv.FormatBool(blocked)),
+		body:        []byte(robotsTxt),
+		contentType: "text/plain",
+	}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// UpdateCustomRule replaces the robots.txt content and the blocked flag of the custom rule.
+func (c *Client) UpdateCustomRule(ctx context.Context, id int, robotsTxt string, blocked bool) (*Rule, error) {
+	return c.updateCustomRule(ctx, urlQuery("id", strconv.Itoa(id), "blocked", strconv.FormatBool(blocked)),
+		robotsTxt)
+}
+
+// UpdateCustomRuleByUrl is UpdateCustomRule for the rule of the domain of the url.
+func (c *Client) UpdateCustomRuleByUrl(ctx context.Context, url string, robotsTxt string,
+	blocked bool) (*Rule, error) {
+	return c.updateCustomRule(ctx, urlQuery("url", url, "blocked", strconv.FormatBool(blocked)), robotsTxt)
+}
+
+func (c *Client) updateCustomRule(ctx context.Context, query url.Values, robotsTxt string) (*Rule, error) {
+	var rule Rule
+	if err := c.doJSON(ctx, request{
+		method:      http.MethodPut,
+		path:        "/custom-rule",
+		query:       query,
+		body:        []byte(robotsTxt),
+		contentType: "text/plain",
+		retry:       true,
+	}, &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// DeleteCustomRule deletes the custom rule by the id. The request is not retried.
+func (c *Client) DeleteCustomRule(ctx context.Context, id int) error {
+	return c.doJSON(ctx, request{
+		method: http.MethodDelete,
+		path:   "/custom-rule",
+		query:  urlQuery("id", strconv.Itoa(id)),
+	}, nil)
+}
+
+// CompareCustomRule evaluates the sample urls and user agents against the custom rule and the live robots.txt
+// of its domain.
+func (c *Client) CompareCustomRule(ctx context.Context, id int,
+	samples RuleComparisonRequest) (*RuleComparisonResponse, error) {
+	body, err := jsonBody(samples)
+	if err != nil {
+		return nil, err
+	}
+	var resp RuleComparisonResponse
+	if err = c.doJSON(ctx, request{
+		method:      http.MethodPost,
+		path:        "/custom-rule/compare",
+		query:       urlQuery("id", strconv.Itoa(id)),
+		body:        body,
+		contentType: "application/json",
+		retry:       true,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetDomainAliases gets all domain aliases, or the given alias if it is not empty.
+func (c *Client) GetDomainAliases(ctx context.Context, alias string) ([]DomainAlias, error) {
+	aliases := make([]DomainAlias, 0)
+	if err := c.doJSON(ctx, request{
+		method: http.MethodGet,
+		path:   "/domain-alias",
+		query:  urlQuery("alias", alias),
+		retry:  true,
+	}, &aliases); err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// CreateDomainAlias makes the custom rule of the domain apply to the alias domain. Returns the id of the alias.
+// The request is not retried.
+func (c *Client) CreateDomainAlias(ctx context.Context, alias string, domain string) (int, error) {
+	var resp struct {
+		ID int `json:"id"`
+	}
+	if err := c.doJSON(ctx, request{
+		method: http.MethodPost,
+		path:   "/domain-alias",
+		query:  urlQuery("alias", alias, "domain", domain),
+	}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// DeleteDomainAlias deletes the domain alias by the id. The request is not retried.
+func (c *Client) DeleteDomainAlias(ctx context.Context, id int) error {
+	return c.doJSON(ctx, request{
+		method: http.MethodDelete,
+		path:   "/domain-alias",
+		query:  urlQuery("id", strconv.Itoa(id)),
+	}, nil)
+}
@@ -0,0 +1,56 @@
+// Command psl-update replaces the embedded Public Suffix List with a local copy of
+// https://publicsuffix.org/list/public_suffix_list.dat. Rebuild the service after the update.
+//
+// Usage (from the repository root):
+//
+//	go run ./cmd/psl-update -src /path/to/public_suffix_list.dat
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/IliaW/rule-api/internal/psl"
+)
+
+const icannMarker = "===BEGIN ICANN DOMAINS==="
+
+func main() {
+	src := flag.String("src", "", "path to the downloaded public_suffix_list.dat")
+	dst := flag.String("dst", "internal/psl/public_suffix_list.dat", "path to the embedded list")
+	flag.Parse()
+
+	if *src == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := update(*src, *dst); err != nil {
+		fmt.Fprintf(os.Stderr, "psl-update: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func update(src string, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if !bytes.Contains(data, []byte(icannMarker)) {
+		return fmt.Errorf("'%s' does not look like the public suffix list", src)
+	}
+	newList, err := psl.Parse(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if _, err = os.Stat(dst); err != nil {
+		return fmt.Errorf("embedded list not found, run the command from the repository root. %s", err.Error())
+	}
+	if err = os.WriteFile(dst, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("updated %s: %d rules (was %d)\n", dst, newList.Size(), psl.Default().Size())
+
+	return nil
+}
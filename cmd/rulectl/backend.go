@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/IliaW/rule-api/client"
+	"github.com/IliaW/rule-api/config"
+	"github.com/IliaW/rule-api/engine"
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/internal/persistence"
+	"github.com/IliaW/rule-api/util"
+	_ "github.com/lib/pq"
+)
+
+// listPageSize is the page size used to list all rules.
+const listPageSize = 500
+
+// backend is where the checks are made and the rules are kept: the running server or the database.
+// ref is the id or the url of the rule.
+type backend interface {
+	check(ctx context.Context, url string, userAgent string, purpose string) (*model.AllowedCrawlResponse, error)
+	listRules(ctx context.Context) ([]*model.Rule, error)
+	getRule(ctx context.Context, ref string) (*model.Rule, error)
+	createRule(ctx context.Context, url string, robotsTxt string, blocked bool) (int, error)
+	updateRule(ctx context.Context, ref string, robotsTxt string, blocked bool) (*model.Rule, error)
+	deleteRule(ctx context.Context, id int) error
+	// fetchRobots fetches the live robots.txt file of the url origin as the service sees it
+	fetchRobots(ctx context.Context, url string) (*model.TargetResponse, error)
+}
+
+// httpBackend uses the Rule API. The robots.txt files are fetched locally.
+type httpBackend struct {
+	client    *client.Client
+	evaluator *engine.Evaluator
+}
+
+func newHttpBackend(server string, apiKey string, timeout time.Duration) *httpBackend {
+	httpClient := &http.Client{Timeout: timeout}
+	return &httpBackend{
+		client:    client.New(server, client.WithApiKey(apiKey), client.WithHttpClient(httpClient)),
+		evaluator: engine.New(engine.Options{}, nil, nil, engine.NewHttpFetcher(httpClient, "rulectl", 0)),
+	}
+}
+
+func (b *httpBackend) check(ctx context.Context, url string, userAgent string,
+	purpose string) (*model.AllowedCrawlResponse, error) {
+	return b.client.CheckCrawl(ctx, url, userAgent, purpose)
+}
+
+func (b *httpBackend) listRules(ctx context.Context) ([]*model.Rule, error) {
+	rules := make([]*model.Rule, 0)
+	opts := client.ListRulesOptions{Sort: model.RuleSortDomain, Limit: listPageSize}
+	for {
+		page, err := b.client.ListCustomRules(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, page.Rules...)
+		if page.NextCursor == "" {
+			return rules, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+func (b *httpBackend) getRule(ctx context.Context, ref string) (*model.Rule, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return b.client.GetCustomRule(ctx, id)
+	}
+	return b.client.GetCustomRuleByUrl(ctx, ref)
+}
+
+func (b *httpBackend) createRule(ctx context.Context, url string, robotsTxt string, blocked bool) (int, error) {
+	return b.client.CreateCustomRule(ctx, url, robotsTxt, blocked)
+}
+
+func (b *httpBackend) updateRule(ctx context.Context, ref string, robotsTxt string, blocked bool) (*model.Rule, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return b.client.UpdateCustomRule(ctx, id, robotsTxt, blocked)
+	}
+	return b.client.UpdateCustomRuleByUrl(ctx, ref, robotsTxt, blocked)
+}
+
+func (b *httpBackend) deleteRule(ctx context.Context, id int) error {
+	return b.client.DeleteCustomRule(ctx, id)
+}
+
+func (b *httpBackend) fetchRobots(ctx context.Context, url string) (*model.TargetResponse, error) {
+	return b.evaluator.RobotsTxt(ctx, url)
+}
+
+// dbBackend uses the database of the service. The checks are made locally with the rules of the database.
+type dbBackend struct {
+	db        *sql.DB
+	ruleRepo  persistence.RuleStorage
+	evaluator *engine.Evaluator
+}
+
+func newDbBackend(ctx context.Context) (*dbBackend, error) {
+	cfg := config.MustLoad()
+	if cfg.DbSettings == nil {
+		return nil, errors.New("the 'database' settings are missing in config.yaml")
+	}
+	connStr := fmt.Sprintf("user=%s password=%s host=%s port=%s dbname=%s sslmode=disable",
+		cfg.DbSettings.User,
+		cfg.DbSettings.Password,
+		cfg.DbSettings.Host,
+		cfg.DbSettings.Port,
+		cfg.DbSettings.Name,
+	)
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to connect to the database. %w", err)
+	}
+
+	httpClient := &http.Client{}
+	var maxResponseSize int64
+	if cfg.HttpClientSettings != nil {
+		httpClient.Timeout = cfg.HttpClientSettings.RequestTimeout
+		maxResponseSize = cfg.HttpClientSettings.MaxResponseSizeKb * 1024
+	}
+	ruleRepo := persistence.NewRuleRepository(db)
+	return &dbBackend{
+		db:       db,
+		ruleRepo: ruleRepo,
+		evaluator: engine.New(engine.OptionsFromConfig(cfg), ruleRepo, nil,
+			engine.NewHttpFetcher(httpClient, cfg.RuleUserAgent, maxResponseSize)),
+	}, nil
+}
+
+func (b *dbBackend) close() {
+	if b.db != nil {
+		_ = b.db.Close()
+	}
+}
+
+func (b *dbBackend) check(ctx context.Context, url string, userAgent string,
+	purpose string) (*model.AllowedCrawlResponse, error) {
+	// as in the API, the failure of the check is described by the verdict
+	verdict, _ := b.evaluator.CheckPurpose(ctx, url, userAgent, purpose)
+	return &verdict, nil
+}
+
+func (b *dbBackend) listRules(context.Context) ([]*model.Rule, error) {
+	rules := make([]*model.Rule, 0)
+	filter := &model.RuleFilter{SortBy: model.RuleSortDomain, Order: model.SortAsc, Limit: listPageSize}
+	for {
+		page, err := b.ruleRepo.List(filter)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, page...)
+		if len(page) < filter.Limit {
+			return rules, nil
+		}
+		filter.After = page[len(page)-1]
+	}
+}
+
+func (b *dbBackend) getRule(_ context.Context, ref string) (*model.Rule, error) {
+	if _, err := strconv.Atoi(ref); err == nil {
+		return b.ruleRepo.GetById(ref)
+	}
+	return b.ruleRepo.GetByUrl(ref)
+}
+
+func (b *dbBackend) createRule(_ context.Context, url string, robotsTxt string, blocked bool) (int, error) {
+	domain, err := util.GetDomain(url)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse url. %w", err)
+	}
+	id, err := b.ruleRepo.Save(&model.Rule{Domain: domain, RobotsTxt: robotsTxt, Blocked: blocked})
+	return int(id), err
+}
+
+func (b *dbBackend) updateRule(ctx context.Context, ref string, robotsTxt string, blocked bool) (*model.Rule, error) {
+	rule, err := b.getRule(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if rule.RobotsTxt == robotsTxt && rule.Blocked == blocked {
+		return rule, nil
+	}
+	rule.RobotsTxt = robotsTxt
+	rule.Blocked = blocked
+	return b.ruleRepo.Update(rule)
+}
+
+func (b *dbBackend) deleteRule(_ context.Context, id int) error {
+	return b.ruleRepo.Delete(strconv.Itoa(id))
+}
+
+func (b *dbBackend) fetchRobots(ctx context.Context, url string) (*model.TargetResponse, error) {
+	return b.evaluator.RobotsTxt(ctx, url)
+}
+
+// isNotFound reports whether the rule does not exist in either backend.
+func isNotFound(err error) bool {
+	return client.IsNotFound(err) || errors.Is(err, persistence.ErrNotFound)
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/util"
+)
+
+type cli struct {
+	backend backend
+	json    bool
+	stdin   io.Reader
+	stdout  io.Writer
+}
+
+// importSummary is the result of 'rules import'.
+type importSummary struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+}
+
+// robotsOutput is the result of 'robots fetch'.
+type robotsOutput struct {
+	Url        string               `json:"url"`
+	StatusCode int                  `json:"status_code"`
+	Content    *model.RobotsContent `json:"content,omitempty"`
+	RobotsTxt  string               `json:"robots_txt"`
+}
+
+func (c *cli) execute(ctx context.Context, args []string) error {
+	switch args[0] {
+	case "check":
+		return c.check(ctx, args[1:])
+	case "rules":
+		if len(args) < 2 {
+			return usageError("rules: the subcommand is required: list, get, create, update, delete, import, export")
+		}
+		switch args[1] {
+		case "list":
+			return c.listRules(ctx)
+		case "get":
+			return c.getRule(ctx, args[2:])
+		case "create":
+			return c.createRule(ctx, args[2:])
+		case "update":
+			return c.updateRule(ctx, args[2:])
+		case "delete":
+			return c.deleteRule(ctx, args[2:])
+		case "export":
+			return c.exportRules(ctx, args[2:])
+		case "import":
+			return c.importRules(ctx, args[2:])
+		}
+		return usageError(fmt.Sprintf("rules: unknown subcommand '%s'", args[1]))
+	case "robots":
+		if len(args) < 2 || args[1] != "fetch" {
+			return usageError("robots: the subcommand is required: fetch")
+		}
+		return c.fetchRobots(ctx, args[2:])
+	}
+	return usageError(fmt.Sprintf("unknown command '%s'", args[0]))
+}
+
+func (c *cli) check(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	purpose := fs.String("purpose", "", "crawl purpose from the 'crawl_purposes' config, e.g. ai-training")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if fs.NArg() != 2 {
+		return usageError("check: <url> and <user_agent> are required")
+	}
+
+	verdict, err := c.backend.check(ctx, fs.Arg(0), fs.Arg(1), *purpose)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJson(verdict)
+	}
+	return c.printTable([]string{"URL", "USER AGENT", "ALLOWED", "BLOCKED", "STATUS", "SOURCE", "ERROR"},
+		[][]string{{fs.Arg(0), fs.Arg(1), strconv.FormatBool(verdict.IsAllowed), strconv.FormatBool(verdict.Blocked),
+			strconv.Itoa(verdict.StatusCode), verdict.Source, verdict.Error}})
+}
+
+func (c *cli) listRules(ctx context.Context) error {
+	rules, err := c.backend.listRules(ctx)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJson(rules)
+	}
+	rows := make([][]string, 0, len(rules))
+	for _, rule := range rules {
+		rows = append(rows, []string{strconv.Itoa(rule.ID), rule.Domain, strconv.FormatBool(rule.Blocked),
+			formatTime(rule.UpdatedAt)})
+	}
+	return c.printTable([]string{"ID", "DOMAIN", "BLOCKED", "UPDATED AT"}, rows)
+}
+
+func (c *cli) getRule(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return usageError("rules get: <id|url> is required")
+	}
+	rule, err := c.backend.getRule(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return c.printRule(rule)
+}
+
+func (c *cli) createRule(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rules create", flag.ContinueOnError)
+	blocked := fs.Bool("blocked", false, "block the domain from being crawled")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if fs.NArg() != 2 {
+		return usageError("rules create: <url> and <robots.txt file|-> are required")
+	}
+	robotsTxt, err := c.readFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	id, err := c.backend.createRule(ctx, fs.Arg(0), string(robotsTxt), *blocked)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJson(map[string]int{"id": id})
+	}
+	_, err = fmt.Fprintf(c.stdout, "created rule %d\n", id)
+	return err
+}
+
+func (c *cli) updateRule(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rules update", flag.ContinueOnError)
+	blocked := fs.Bool("blocked", false, "block the domain from being crawled")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if fs.NArg() != 2 {
+		return usageError("rules update: <id|url> and <robots.txt file|-> are required")
+	}
+	robotsTxt, err := c.readFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	rule, err := c.backend.updateRule(ctx, fs.Arg(0), string(robotsTxt), *blocked)
+	if err != nil {
+		return err
+	}
+	return c.printRule(rule)
+}
+
+func (c *cli) deleteRule(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return usageError("rules delete: <id> is required")
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return usageError("rules delete: <id> must be an integer")
+	}
+
+	if err = c.backend.deleteRule(ctx, id); err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJson(map[string]int{"id": id})
+	}
+	_, err = fmt.Fprintf(c.stdout, "deleted rule %d\n", id)
+	return err
+}
+
+// exportRules writes all rules as the json array, the input of 'rules import'. The output format is ignored.
+func (c *cli) exportRules(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		return usageError("rules export: too many arguments")
+	}
+	rules, err := c.backend.listRules(ctx)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return c.printJson(rules)
+	}
+
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(args[0], append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.stdout, "exported %d rules to %s\n", len(rules), args[0])
+	return err
+}
+
+// importRules creates the rules of the exported json array. The existing rules of the same domains are updated.
+func (c *cli) importRules(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return usageError("rules import: <file|-> is required")
+	}
+	data, err := c.readFile(args[0])
+	if err != nil {
+		return err
+	}
+	var rules []*model.Rule
+	if err = json.Unmarshal(data, &rules); err != nil {
+		return fmt.Errorf("failed to parse '%s'. %w", args[0], err)
+	}
+
+	var summary importSummary
+	for _, rule := range rules {
+		url := util.WithScheme(rule.Domain, "https")
+		existing, err := c.backend.getRule(ctx, url)
+		switch {
+		case isNotFound(err):
+			if _, err = c.backend.createRule(ctx, url, rule.RobotsTxt, rule.Blocked); err != nil {
+				return fmt.Errorf("failed to create the rule of '%s'. %w", rule.Domain, err)
+			}
+			summary.Created++
+		case err != nil:
+			return fmt.Errorf("failed to get the rule of '%s'. %w", rule.Domain, err)
+		case existing.RobotsTxt == rule.RobotsTxt && existing.Blocked == rule.Blocked:
+			summary.Unchanged++
+		default:
+			if _, err = c.backend.updateRule(ctx, strconv.Itoa(existing.ID), rule.RobotsTxt,
+				rule.Blocked); err != nil {
+				return fmt.Errorf("failed to update the rule of '%s'. %w", rule.Domain, err)
+			}
+			summary.Updated++
+		}
+	}
+
+	if c.json {
+		return c.printJson(summary)
+	}
+	_, err = fmt.Fprintf(c.stdout, "created %d, updated %d, unchanged %d\n", summary.Created, summary.Updated,
+		summary.Unchanged)
+	return err
+}
+
+func (c *cli) fetchRobots(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return usageError("robots fetch: <url> is required")
+	}
+	url := args[0]
+	if !util.HasScheme(url) {
+		url = util.WithScheme(url, "https")
+	}
+
+	tResp, err := c.backend.fetchRobots(ctx, url)
+	if err != nil {
+		return err
+	}
+	output := robotsOutput{
+		Url:        tResp.Url,
+		StatusCode: tResp.StatusCode,
+		Content:    tResp.Content,
+		RobotsTxt:  string(tResp.Body),
+	}
+	if c.json {
+		return c.printJson(output)
+	}
+
+	fields := [][]string{{"URL:", output.Url}, {"STATUS:", strconv.Itoa(output.StatusCode)}}
+	if output.Content != nil {
+		fields = append(fields, []string{"CONTENT TYPE:", output.Content.ContentType},
+			[]string{"VALID:", strconv.FormatBool(output.Content.Valid)})
+	}
+	return c.printFields(fields, output.RobotsTxt)
+}
+
+func (c *cli) printRule(rule *model.Rule) error {
+	if c.json {
+		return c.printJson(rule)
+	}
+	return c.printFields([][]string{
+		{"ID:", strconv.Itoa(rule.ID)},
+		{"DOMAIN:", rule.Domain},
+		{"BLOCKED:", strconv.FormatBool(rule.Blocked)},
+		{"CREATED AT:", formatTime(rule.CreatedAt)},
+		{"UPDATED AT:", formatTime(rule.UpdatedAt)},
+	}, rule.RobotsTxt)
+}
+
+// readFile reads the file, or stdin if the name is '-'.
+func (c *cli) readFile(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(c.stdin)
+	}
+	return os.ReadFile(name)
+}
+
+func (c *cli) printJson(v any) error {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func (c *cli) printTable(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// printFields prints the name-value pairs followed by the file content.
+func (c *cli) printFields(fields [][]string, content string) error {
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 1, ' ', 0)
+	for _, field := range fields {
+		_, _ = fmt.Fprintln(w, strings.Join(field, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if content == "" {
+		return nil
+	}
+	_, err := fmt.Fprintf(c.stdout, "\n%s\n", strings.TrimRight(content, "\n"))
+	return err
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
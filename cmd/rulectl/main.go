@@ -0,0 +1,118 @@
+// Command rulectl checks the urls and manages the custom rules of the Rule API through the running server or
+// directly in the database.
+//
+// Usage:
+//
+//	rulectl [flags] check [-purpose purpose] <url> <user_agent>
+//	rulectl [flags] rules list
+//	rulectl [flags] rules get <id|url>
+//	rulectl [flags] rules create [-blocked] <url> <robots.txt file|->
+//	rulectl [flags] rules update [-blocked] <id|url> <robots.txt file|->
+//	rulectl [flags] rules delete <id>
+//	rulectl [flags] rules export [file]
+//	rulectl [flags] rules import <file|->
+//	rulectl [flags] robots fetch <url>
+//
+// The server at -server is used by default. With -db the database settings and the check options are read from
+// config.yaml in the working directory and the environment, as in the server. '-' reads the file from stdin.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	defaultServer = "http://localhost:8081/rule/v1"
+	outputTable   = "table"
+	outputJson    = "json"
+)
+
+// usageError is the error of the command line arguments. The usage is printed along with it.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func main() {
+	ctx := context.Background()
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "rulectl: %s\n", err.Error())
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+// run parses the global flags, connects to the backend and executes the command.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("rulectl", flag.ContinueOnError)
+	server := fs.String("server", envOr("RULECTL_SERVER", defaultServer), "base url of the Rule API "+
+		"(env RULECTL_SERVER)")
+	apiKey := fs.String("api-key", os.Getenv("RULECTL_API_KEY"), "api key of the custom rule endpoints "+
+		"(env RULECTL_API_KEY)")
+	useDb := fs.Bool("db", false, "use the database from config.yaml instead of the server")
+	output := fs.String("o", outputTable, "output format: table or json")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout of the command")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rulectl [flags] <command> [args]\n\n"+
+			"Commands:\n"+
+			"  check [-purpose purpose] <url> <user_agent>\n"+
+			"  rules list\n"+
+			"  rules get <id|url>\n"+
+			"  rules create [-blocked] <url> <robots.txt file|->\n"+
+			"  rules update [-blocked] <id|url> <robots.txt file|->\n"+
+			"  rules delete <id>\n"+
+			"  rules export [file]\n"+
+			"  rules import <file|->\n"+
+			"  robots fetch <url>\n\n"+
+			"Flags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return usageError(err.Error())
+	}
+	if *output != outputTable && *output != outputJson {
+		return usageError(fmt.Sprintf("unsupported output format '%s'", *output))
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return usageError("the command is required")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	var b backend
+	if *useDb {
+		dbBackend, err := newDbBackend(ctx)
+		if err != nil {
+			return err
+		}
+		defer dbBackend.close()
+		b = dbBackend
+	} else {
+		b = newHttpBackend(*server, *apiKey, *timeout)
+	}
+
+	c := &cli{backend: b, json: *output == outputJson, stdin: stdin, stdout: stdout}
+	return c.execute(ctx, fs.Args())
+}
+
+func envOr(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/internal/persistence"
+	storageMock "github.com/IliaW/rule-api/internal/persistence/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_Run_HttpBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rule/v1/crawl-allowed":
+			_, _ = w.Write([]byte("{\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"source\":\"live\"}"))
+		case "/rule/v1/custom-rule":
+			if r.Header.Get("X-API-Key") != "key" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte("{\"code\":\"UNAUTHORIZED\",\"message\":\"invalid api key\"}"))
+				return
+			}
+			_, _ = w.Write([]byte("{\"id\":1,\"domain\":\"example.com\",\"blocked\":true," +
+				"\"robots_txt\":\"User-agent: *\\nDisallow: /\\n\",\"created_at\":\"2025-01-01T12:00:00Z\"," +
+				"\"updated_at\":\"2025-01-02T12:00:00Z\"}"))
+		case "/rule/v1/custom-rules":
+			_, _ = w.Write([]byte("{\"rules\":[{\"id\":1,\"domain\":\"example.com\",\"blocked\":true," +
+				"\"updated_at\":\"2025-01-02T12:00:00Z\"},{\"id\":12,\"domain\":\"example.org\"}]}"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	testSet := []struct {
+		name     string
+		args     []string
+		expected string
+		err      string
+	}{
+		{
+			name: "check table",
+			args: []string{"check", "https://example.com/private", "bot"},
+			expected: "URL                          USER AGENT  ALLOWED  BLOCKED  STATUS  SOURCE  ERROR\n" +
+				"https://example.com/private  bot         false    false    200     live    \n",
+		},
+		{
+			name: "check json",
+			args: []string{"-o", "json", "check", "https://example.com/private", "bot"},
+			expected: "{\n  \"is_allowed\": false,\n  \"blocked\": false,\n  \"status_code\": 200,\n  \"error\": \"\",\n" +
+				"  \"source\": \"live\"\n}\n",
+		},
+		{
+			name: "get rule",
+			args: []string{"-api-key", "key", "rules", "get", "1"},
+			expected: "ID:         1\nDOMAIN:     example.com\nBLOCKED:    true\nCREATED AT: 2025-01-01T12:00:00Z\n" +
+				"UPDATED AT: 2025-01-02T12:00:00Z\n\nUser-agent: *\nDisallow: /\n",
+		},
+		{
+			name: "list rules",
+			args: []string{"rules", "list"},
+			expected: "ID  DOMAIN       BLOCKED  UPDATED AT\n" +
+				"1   example.com  true     2025-01-02T12:00:00Z\n" +
+				"12  example.org  false    \n",
+		},
+		{
+			name: "missing api key",
+			args: []string{"rules", "get", "1"},
+			err:  "rule-api: 401 UNAUTHORIZED: invalid api key",
+		},
+		{
+			name: "unknown command",
+			args: []string{"rules", "rename"},
+			err:  "rules: unknown subcommand 'rename'",
+		},
+	}
+
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			var stdout bytes.Buffer
+			args := append([]string{"-server", server.URL + "/rule/v1"}, test.args...)
+
+			err := run(context.Background(), args, strings.NewReader(""), &stdout)
+
+			if test.err != "" {
+				assert.EqualError(tt, err, test.err)
+				return
+			}
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.expected, stdout.String())
+		})
+	}
+}
+
+func Test_ImportRules(t *testing.T) {
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("GetByUrl", "https://same.com").Return(&model.Rule{ID: 1, Domain: "same.com",
+		RobotsTxt: "User-agent: *\nAllow: /"}, nil)
+	ruleRepo.On("GetByUrl", "https://new.com").Return(nil,
+		fmt.Errorf("rule with domain 'new.com' %w", persistence.ErrNotFound))
+	ruleRepo.On("Save", mock.MatchedBy(func(rule *model.Rule) bool {
+		return rule.Domain == "new.com" && rule.Blocked
+	})).Return(int64(2), nil)
+	changed := &model.Rule{ID: 3, Domain: "changed.com", RobotsTxt: "User-agent: *\nAllow: /"}
+	ruleRepo.On("GetByUrl", "https://changed.com").Return(changed, nil)
+	ruleRepo.On("GetById", "3").Return(changed, nil)
+	ruleRepo.On("Update", mock.MatchedBy(func(rule *model.Rule) bool {
+		return rule.ID == 3 && rule.RobotsTxt == "User-agent: *\nDisallow: /"
+	})).Return(&model.Rule{ID: 3, UpdatedAt: time.Now()}, nil)
+
+	var stdout bytes.Buffer
+	c := &cli{
+		backend: &dbBackend{ruleRepo: ruleRepo},
+		stdin: strings.NewReader("[{\"domain\":\"same.com\",\"robots_txt\":\"User-agent: *\\nAllow: /\"}," +
+			"{\"domain\":\"new.com\",\"blocked\":true,\"robots_txt\":\"User-agent: *\\nDisallow: /\"}," +
+			"{\"domain\":\"changed.com\",\"robots_txt\":\"User-agent: *\\nDisallow: /\"}]"),
+		stdout: &stdout,
+	}
+
+	err := c.execute(context.Background(), []string{"rules", "import", "-"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "created 1, updated 1, unchanged 1\n", stdout.String())
+}
@@ -10,25 +10,66 @@ import (
 )
 
 type Config struct {
-	Env                string            `mapstructure:"env"`
-	LogLevel           string            `mapstructure:"log_level"`
-	LogType            string            `mapstructure:"log_type"`
-	ServiceName        string            `mapstructure:"service_name"`
-	Port               string            `mapstructure:"port"`
-	Version            string            `mapstructure:"version"`
-	CorsMaxAgeHours    time.Duration     `mapstructure:"cors_max_age_hours"`
-	RuleApiUrlPath     string            `mapstructure:"rule_api_url_path"`
-	MaxBodySize        int64             `mapstructure:"max_body_size"`
-	RuleUserAgent      string            `mapstructure:"rule_user_agent"`
-	CacheSettings      *CacheConfig      `mapstructure:"cache"`
-	DbSettings         *DatabaseConfig   `mapstructure:"database"`
-	HttpClientSettings *HttpClientConfig `mapstructure:"http_client"`
-	TelemetrySettings  *TelemetryConfig  `mapstructure:"telemetry"`
+	Env                 string              `mapstructure:"env"`
+	LogLevel            string              `mapstructure:"log_level"`
+	LogType             string              `mapstructure:"log_type"`
+	ServiceName         string              `mapstructure:"service_name"`
+	Port                string              `mapstructure:"port"`
+	Version             string              `mapstructure:"version"`
+	CorsMaxAgeHours     time.Duration       `mapstructure:"cors_max_age_hours"`
+	RuleApiUrlPath      string              `mapstructure:"rule_api_url_path"`
+	MaxBodySize         int64               `mapstructure:"max_body_size"`
+	RuleUserAgent       string              `mapstructure:"rule_user_agent"`
+	InvalidRobotsTxt    string              `mapstructure:"invalid_robots_txt"`
+	CrawlPurposes       map[string][]string `mapstructure:"crawl_purposes"`
+	RuleAliasSettings   *RuleAliasConfig    `mapstructure:"rule_alias"`
+	CacheSettings       *CacheConfig        `mapstructure:"cache"`
+	DbSettings          *DatabaseConfig     `mapstructure:"database"`
+	HttpClientSettings  *HttpClientConfig   `mapstructure:"http_client"`
+	TelemetrySettings   *TelemetryConfig    `mapstructure:"telemetry"`
+	GrpcSettings        *GrpcConfig         `mapstructure:"grpc"`
+	CrawlStreamSettings *CrawlStreamConfig  `mapstructure:"crawl_stream"`
+	JobSettings         *JobConfig          `mapstructure:"jobs"`
+}
+
+type JobConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Workers         int           `mapstructure:"workers"`
+	Concurrency     int           `mapstructure:"concurrency"`
+	ChunkSize       int           `mapstructure:"chunk_size"`
+	MaxUploadSizeMb int64         `mapstructure:"max_upload_size_mb"`
+	PollInterval    time.Duration `mapstructure:"poll_interval"`
+	StaleAfter      time.Duration `mapstructure:"stale_after"`
+	Retention       time.Duration `mapstructure:"retention"`
+}
+
+type CrawlStreamConfig struct {
+	Concurrency     int  `mapstructure:"concurrency"`
+	MaxLineSizeKb   int  `mapstructure:"max_line_size_kb"`
+	ExemptBodyLimit bool `mapstructure:"exempt_body_limit"`
+}
+
+type GrpcConfig struct {
+	Enabled          bool   `mapstructure:"enabled"`
+	Port             string `mapstructure:"port"`
+	MaxBatchSize     int    `mapstructure:"max_batch_size"`
+	BatchConcurrency int    `mapstructure:"batch_concurrency"`
+	Reflection       bool   `mapstructure:"reflection"`
+}
+
+type RuleAliasConfig struct {
+	WwwFallback       bool `mapstructure:"www_fallback"`
+	AliasTable        bool `mapstructure:"alias_table"`
+	RegistrableDomain bool `mapstructure:"registrable_domain"`
 }
 
 type CacheConfig struct {
-	Servers         []string      `mapstructure:"servers"`
-	TtlForRobotsTxt time.Duration `mapstructure:"ttl_for_robots_txt"`
+	Servers              []string      `mapstructure:"servers"`
+	TtlForRobotsTxt      time.Duration `mapstructure:"ttl_for_robots_txt"`
+	TtlForAiTxt          time.Duration `mapstructure:"ttl_for_ai_txt"`
+	TtlForTdmRep         time.Duration `mapstructure:"ttl_for_tdmrep"`
+	TtlForPageDirectives time.Duration `mapstructure:"ttl_for_page_directives"`
+	CompressRobotsTxt    bool          `mapstructure:"compress_robots_txt"`
 }
 
 type DatabaseConfig struct {
@@ -43,15 +84,49 @@ type DatabaseConfig struct {
 }
 
 type HttpClientConfig struct {
-	RequestTimeout            time.Duration `mapstructure:"request_timeout"`
-	MaxIdleConnections        int           `mapstructure:"max_idle_connections"`
-	MaxIdleConnectionsPerHost int           `mapstructure:"max_idle_connections_per_host"`
-	MaxConnectionsPerHost     int           `mapstructure:"max_connections_per_host"`
-	IdleConnectionTimeout     time.Duration `mapstructure:"idle_connection_timeout"`
-	TlsHandshakeTimeout       time.Duration `mapstructure:"tls_handshake_timeout"`
-	DialTimeout               time.Duration `mapstructure:"dial_timeout"`
-	DialKeepAlive             time.Duration `mapstructure:"dial_keep_alive"`
-	TlsInsecureSkipVerify     bool          `mapstructure:"tls_insecure_skip_verify"`
+	RequestTimeout            time.Duration         `mapstructure:"request_timeout"`
+	MaxIdleConnections        int                   `mapstructure:"max_idle_connections"`
+	MaxIdleConnectionsPerHost int                   `mapstructure:"max_idle_connections_per_host"`
+	MaxConnectionsPerHost     int                   `mapstructure:"max_connections_per_host"`
+	IdleConnectionTimeout     time.Duration         `mapstructure:"idle_connection_timeout"`
+	TlsHandshakeTimeout       time.Duration         `mapstructure:"tls_handshake_timeout"`
+	DialTimeout               time.Duration         `mapstructure:"dial_timeout"`
+	DialKeepAlive             time.Duration         `mapstructure:"dial_keep_alive"`
+	TlsInsecureSkipVerify     bool                  `mapstructure:"tls_insecure_skip_verify"`
+	MaxResponseSizeKb         int64                 `mapstructure:"max_response_size_kb"`
+	SsrfProtection            *SsrfProtectionConfig `mapstructure:"ssrf_protection"`
+	Proxy                     *ProxyConfig          `mapstructure:"proxy"`
+	Dns                       *DnsConfig            `mapstructure:"dns"`
+}
+
+type DnsConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Server        string        `mapstructure:"server"`
+	PositiveTtl   time.Duration `mapstructure:"positive_ttl"`
+	NegativeTtl   time.Duration `mapstructure:"negative_ttl"`
+	HappyEyeballs bool          `mapstructure:"happy_eyeballs"`
+	FallbackDelay time.Duration `mapstructure:"fallback_delay"`
+}
+
+type SsrfProtectionConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	AllowedHosts []string `mapstructure:"allowed_hosts"`
+	AllowedCidrs []string `mapstructure:"allowed_cidrs"`
+}
+
+type ProxyConfig struct {
+	Url       string                `mapstructure:"url"`
+	Username  string                `mapstructure:"username"`
+	Password  string                `mapstructure:"password"`
+	Overrides []ProxyOverrideConfig `mapstructure:"overrides"`
+	NoProxy   []string              `mapstructure:"no_proxy"`
+}
+
+type ProxyOverrideConfig struct {
+	Pattern  string `mapstructure:"pattern"`
+	Url      string `mapstructure:"url"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
 }
 
 type TelemetryConfig struct {
@@ -0,0 +1,12 @@
+-- The alias table of the www and apex fallback. Apply to the databases created before the domain_alias table of
+-- init.sql, before 002_widen_domain_columns.sql.
+CREATE TABLE IF NOT EXISTS web_crawler.domain_alias
+(
+    id         SERIAL PRIMARY KEY,
+    alias      VARCHAR(253) NOT NULL UNIQUE,
+    domain     VARCHAR(253) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON web_crawler.domain_alias TO web_crawler_rw_user;
+GRANT USAGE, SELECT ON SEQUENCE web_crawler.domain_alias_id_seq TO web_crawler_rw_user;
@@ -0,0 +1,7 @@
+-- The domains are canonical IDNA host names, up to 253 characters. Apply to the databases created with the
+-- VARCHAR(80) domain columns of init.sql.
+ALTER TABLE web_crawler.custom_rule
+    ALTER COLUMN domain TYPE VARCHAR(253);
+
+ALTER TABLE web_crawler.domain_alias
+    ALTER COLUMN domain TYPE VARCHAR(253);
@@ -0,0 +1,42 @@
+-- The tables of the asynchronous crawl check jobs. Apply to the databases created before the crawl_job table of
+-- init.sql. The url lists and the results are stored in the database, so any instance runs any job and serves its
+-- result.
+CREATE TABLE IF NOT EXISTS web_crawler.crawl_job
+(
+    id          VARCHAR(36) PRIMARY KEY,
+    status      VARCHAR(20)   NOT NULL DEFAULT 'queued', -- queued, running, completed or failed
+    user_agent  VARCHAR(255)  NOT NULL,
+    purpose     VARCHAR(50)   NOT NULL DEFAULT '',
+    total       INT           NOT NULL DEFAULT 0,
+    processed   INT           NOT NULL DEFAULT 0,
+    allowed     INT           NOT NULL DEFAULT 0,
+    disallowed  INT           NOT NULL DEFAULT 0,
+    failed      INT           NOT NULL DEFAULT 0,
+    error       VARCHAR(1000) NOT NULL DEFAULT '',
+    claimed_by  VARCHAR(36)   NOT NULL DEFAULT '', -- lease of the worker that runs the job
+    created_at  TIMESTAMP              DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP              DEFAULT CURRENT_TIMESTAMP,
+    started_at  TIMESTAMP     NULL,
+    finished_at TIMESTAMP     NULL
+);
+
+CREATE INDEX IF NOT EXISTS crawl_job_status_index ON web_crawler.crawl_job (status, created_at);
+
+CREATE TABLE IF NOT EXISTS web_crawler.crawl_job_url
+(
+    job_id   VARCHAR(36) NOT NULL REFERENCES web_crawler.crawl_job (id) ON DELETE CASCADE,
+    position INT         NOT NULL, -- zero-based number of the url in the uploaded list
+    url      TEXT        NOT NULL,
+    PRIMARY KEY (job_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS web_crawler.crawl_job_result
+(
+    job_id      VARCHAR(36) NOT NULL REFERENCES web_crawler.crawl_job (id) ON DELETE CASCADE,
+    chunk_start INT         NOT NULL, -- position of the first url of the chunk
+    result      TEXT        NOT NULL, -- NDJSON verdicts of the chunk
+    PRIMARY KEY (job_id, chunk_start)
+);
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON web_crawler.crawl_job, web_crawler.crawl_job_url,
+    web_crawler.crawl_job_result TO web_crawler_rw_user;
@@ -0,0 +1,4 @@
+-- The index of the custom rule list sorted by updated_at, see /custom-rules. Apply to the databases created before
+-- the custom_rule_updated_at_index of init.sql. CONCURRENTLY does not lock the table for writes, so the migration
+-- must not run inside a transaction.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS custom_rule_updated_at_index ON web_crawler.custom_rule (updated_at, id);
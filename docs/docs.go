@@ -28,7 +28,7 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "URL to check",
+                        "description": "URL to check. Without a scheme, https is tried first and http is the fallback",
                         "name": "url",
                         "in": "query",
                         "required": true
@@ -39,6 +39,12 @@ const docTemplate = `{
                         "name": "user_agent",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Crawl purpose from the 'crawl_purposes' config. The mapped product tokens are evaluated along with the user agent. 'ai-training' adds the ai.txt verdict",
+                        "name": "purpose",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -51,6 +57,40 @@ const docTemplate = `{
                 }
             }
         },
+        "/crawl-allowed/stream": {
+            "post": {
+                "description": "Read newline-delimited JSON records from the request body and write the verdicts back as NDJSON as each one completes. The records are checked concurrently, so the results are unordered and tagged with the 'index' of the record. Invalid records get a result with the INVALID_REQUEST error code",
+                "consumes": [
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Crawling"
+                ],
+                "summary": "Check crawl permissions for a stream of URLs",
+                "parameters": [
+                    {
+                        "description": "NDJSON records, one per line",
+                        "name": "records",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CrawlStreamRecord"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "NDJSON results, one per line",
+                        "schema": {
+                            "$ref": "#/definitions/model.CrawlStreamResult"
+                        }
+                    }
+                }
+            }
+        },
         "/custom-rule": {
             "get": {
                 "security": [
@@ -86,6 +126,42 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/model.Rule"
                         }
+                    },
+                    "400": {
+                        "description": "Missing or invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is not active",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Custom rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid URL",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
                     }
                 }
             },
@@ -142,6 +218,48 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/model.Rule"
                         }
+                    },
+                    "400": {
+                        "description": "Missing or invalid query parameter or empty body",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is not active",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Custom rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Custom rule for the domain already exists",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid URL",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
                     }
                 }
             },
@@ -192,6 +310,42 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "400": {
+                        "description": "Missing or invalid query parameter or empty body",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is not active",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Custom rule for the domain already exists",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid URL or the domain is too long",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
                     }
                 }
             },
@@ -224,50 +378,1180 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "400": {
+                        "description": "Missing or invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is not active",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Custom rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.AllowedCrawlResponse": {
-            "description": "Is crawl allowed for the domain",
-            "type": "object",
-            "properties": {
-                "blocked": {
-                    "type": "boolean"
-                },
-                "error": {
-                    "type": "string"
-                },
-                "is_allowed": {
-                    "type": "boolean"
-                },
-                "status_code": {
-                    "type": "integer"
+        },
+        "/custom-rule/compare": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Evaluate sample URLs and user agents against the custom rule and the live robots.txt of its domain",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Custom Rule"
+                ],
+                "summary": "Compare a custom rule with the live robots.txt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Custom rule ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "Sample URLs and user agents",
+                        "name": "samples",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RuleComparisonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Comparison result",
+                        "schema": {
+                            "$ref": "#/definitions/model.RuleComparisonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or invalid query parameter or request body",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is not active",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Custom rule not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error or failed to fetch the live robots.txt",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "model.Rule": {
-            "description": "Represents a custom rule for a domain",
-            "type": "object",
-            "properties": {
-                "blocked": {
-                    "type": "boolean"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "domain": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "robots_txt": {
-                    "type": "string"
-                },
-                "updated_at": {
+        "/custom-rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the custom rules page by page. The rules can be filtered by the domain and robots.txt substrings, the blocked flag and the created and updated date ranges",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Custom Rule"
+                ],
+                "summary": "List custom rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive substring of the domain",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive substring of the robots.txt content",
+                        "name": "robots_txt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only the blocked or only the not blocked rules",
+                        "name": "blocked",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after the time, RFC 3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or before the time, RFC 3339",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updated at or after the time, RFC 3339",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updated at or before the time, RFC 3339",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort key: updated_at (default) or domain",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order: asc or desc. The default is desc for updated_at and asc for domain",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size from 1 to 500. The default is 50",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "'next_cursor' of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Page of custom rules",
+                        "schema": {
+                            "$ref": "#/definitions/model.RulePage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is not active",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/domain-alias": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve all domain aliases or the alias provided in the 'alias' query parameter",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Domain Alias"
+                ],
+                "summary": "Get domain aliases",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Alias domain",
+                        "name": "alias",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Domain aliases",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.DomainAlias"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is not active",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Alias not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create an alias, so the custom rule of the domain is used for the alias domain",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Domain Alias"
+                ],
+                "summary": "Create a domain alias",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Alias domain, e.g. example.co.uk",
+                        "name": "alias",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Domain of the custom rule, e.g. example.com",
+                        "name": "domain",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Alias created successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is not active",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Alias already exists",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid or too long domain, or the alias is the same as the domain",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete an existing domain alias based on the provided ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Domain Alias"
+                ],
+                "summary": "Delete a domain alias by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Alias ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Alias deleted successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or invalid query parameter",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "API key is not active",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Alias not found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upload a list of URLs, one per line, to be checked in the background. The list is sent as the request body or as the 'file' field of a multipart form. Use /jobs/{id} to follow the progress and /jobs/{id}/result to download the results",
+                "consumes": [
+                    "text/plain",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Create a crawl check job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User-Agent",
+                        "name": "user_agent",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Crawl purpose, e.g. 'search-indexing' or 'ai-training'",
+                        "name": "purpose",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "URL list, if sent as a multipart form",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the status and the progress of the job",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Get a crawl check job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Job"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/result": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download the verdicts of the completed job as NDJSON, one line per URL in the order of the uploaded list. 'index' is the zero-based number of the URL in the list.",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Download the result of a crawl check job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "NDJSON results, one per line",
+                        "schema": {
+                            "$ref": "#/definitions/model.CrawlStreamResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/page-directives": {
+            "get": {
+                "description": "Fetch the page and return the directives from the X-Robots-Tag headers and the robots meta tags that apply to the user agent",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crawling"
+                ],
+                "summary": "Get page-level robots directives",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Page URL",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User agent to check",
+                        "name": "user_agent",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Request only the headers (HEAD request). Meta tags are not checked",
+                        "name": "headers_only",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Response object",
+                        "schema": {
+                            "$ref": "#/definitions/model.PageDirectivesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tdm-reservation": {
+            "get": {
+                "description": "Resolve the TDMRep policy for the URL using the /.well-known/tdmrep.json file and the tdm-reservation headers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Crawling"
+                ],
+                "summary": "Get the Text and Data Mining reservation for a URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "URL to check",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Request the URL headers if tdmrep.json has no matching rule. Default is true",
+                        "name": "check_headers",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Response object",
+                        "schema": {
+                            "$ref": "#/definitions/model.TdmReservationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.TdmReservationResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.TdmReservationResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "tdmrep.json or the page can not be fetched",
+                        "schema": {
+                            "$ref": "#/definitions/model.TdmReservationResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.AiTxtVerdict": {
+            "description": "AI-usage opt-out verdict based on the ai.txt file. Returned only for the 'ai-training' purpose",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "error_code": {
+                    "description": "ErrorCode is one of the ErrorCode constants, it is set when the ai.txt file can not be fetched or the status is not 2xx or 4xx",
+                    "type": "string"
+                },
+                "found": {
+                    "type": "boolean"
+                },
+                "is_allowed": {
+                    "type": "boolean"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.AllowedCrawlResponse": {
+            "description": "Is crawl allowed for the domain",
+            "type": "object",
+            "properties": {
+                "ai_txt": {
+                    "$ref": "#/definitions/model.AiTxtVerdict"
+                },
+                "blocked": {
+                    "type": "boolean"
+                },
+                "content": {
+                    "description": "Content is returned when the robots.txt file is fetched from the target",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.RobotsContent"
+                        }
+                    ]
+                },
+                "error": {
+                    "type": "string"
+                },
+                "error_code": {
+                    "description": "ErrorCode is one of the ErrorCode constants. Error is the short message for it",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is the expiration time of the cached robots.txt file",
+                    "type": "string"
+                },
+                "fetched_at": {
+                    "description": "FetchedAt is the fetch time of the robots.txt file or the update time of the custom rule",
+                    "type": "string"
+                },
+                "is_allowed": {
+                    "type": "boolean"
+                },
+                "matched_alias": {
+                    "description": "MatchedAlias is the domain of the custom rule if the rule is found by the www or alias table fallback",
+                    "type": "string"
+                },
+                "registrable_domain": {
+                    "type": "string"
+                },
+                "resolved_origin": {
+                    "description": "ResolvedOrigin is returned when the url is sent without a scheme",
+                    "type": "string"
+                },
+                "robots_url": {
+                    "description": "RobotsUrl is the url the robots.txt file is fetched from, after redirects",
+                    "type": "string"
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Source is where the robots.txt file comes from: custom_rule, cache or live",
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "token_verdicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TokenVerdict"
+                    }
+                }
+            }
+        },
+        "model.CrawlStreamRecord": {
+            "description": "One line of the NDJSON request body of /crawl-allowed/stream",
+            "type": "object",
+            "properties": {
+                "purpose": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CrawlStreamResult": {
+            "description": "One line of the NDJSON response of /crawl-allowed/stream. 'index' is the zero-based line number of the record in the request body, ignoring empty lines",
+            "type": "object",
+            "properties": {
+                "ai_txt": {
+                    "$ref": "#/definitions/model.AiTxtVerdict"
+                },
+                "blocked": {
+                    "type": "boolean"
+                },
+                "content": {
+                    "description": "Content is returned when the robots.txt file is fetched from the target",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.RobotsContent"
+                        }
+                    ]
+                },
+                "error": {
+                    "type": "string"
+                },
+                "error_code": {
+                    "description": "ErrorCode is one of the ErrorCode constants. Error is the short message for it",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is the expiration time of the cached robots.txt file",
+                    "type": "string"
+                },
+                "fetched_at": {
+                    "description": "FetchedAt is the fetch time of the robots.txt file or the update time of the custom rule",
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "is_allowed": {
+                    "type": "boolean"
+                },
+                "matched_alias": {
+                    "description": "MatchedAlias is the domain of the custom rule if the rule is found by the www or alias table fallback",
+                    "type": "string"
+                },
+                "registrable_domain": {
+                    "type": "string"
+                },
+                "resolved_origin": {
+                    "description": "ResolvedOrigin is returned when the url is sent without a scheme",
+                    "type": "string"
+                },
+                "robots_url": {
+                    "description": "RobotsUrl is the url the robots.txt file is fetched from, after redirects",
+                    "type": "string"
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Source is where the robots.txt file comes from: custom_rule, cache or live",
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "token_verdicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TokenVerdict"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DomainAlias": {
+            "description": "Maps an alias domain to the domain of the custom rule, e.g. example.co.uk to example.com",
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ErrorResponse": {
+            "description": "Error of the custom rule and domain alias endpoints. 'code' is one of: BAD_REQUEST, UNAUTHORIZED, FORBIDDEN, NOT_FOUND, CONFLICT, UNPROCESSABLE_ENTITY, INTERNAL_ERROR",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {
+                    "description": "Details is the cause of the client error, e.g. the validation error. It is not returned for the server errors",
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Job": {
+            "description": "The asynchronous crawl check of the uploaded url list. 'processed' is the number of the checked urls out of 'total'",
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disallowed": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "description": "Failed is the number of the urls with an error, e.g. the robots.txt file could not be fetched",
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "purpose": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PageDirectivesResponse": {
+            "description": "Page-level robots directives from the X-Robots-Tag headers and the robots meta tags",
+            "type": "object",
+            "properties": {
+                "directives": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "error_code": {
+                    "description": "ErrorCode is one of the ErrorCode constants, it is set when the page can not be fetched",
+                    "type": "string"
+                },
+                "noai": {
+                    "type": "boolean"
+                },
+                "nofollow": {
+                    "type": "boolean"
+                },
+                "noimageai": {
+                    "type": "boolean"
+                },
+                "noindex": {
+                    "type": "boolean"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RobotsContent": {
+            "description": "What was detected in the fetched robots.txt file. 'detected' is one of: robots.txt, html, json, binary. 'policy' is applied to invalid files: allow_all, disallow_all or parse",
+            "type": "object",
+            "properties": {
+                "bom": {
+                    "type": "boolean"
+                },
+                "charset": {
+                    "type": "string"
+                },
+                "content_encoding": {
+                    "type": "string"
+                },
+                "content_type": {
+                    "type": "string"
+                },
+                "detected": {
+                    "type": "string"
+                },
+                "policy": {
+                    "type": "string"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.Rule": {
+            "description": "Represents a custom rule for a domain",
+            "type": "object",
+            "properties": {
+                "blocked": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "robots_txt": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RuleComparisonRequest": {
+            "description": "Sample URLs and user agents to evaluate against the custom rule and the live robots.txt",
+            "type": "object",
+            "properties": {
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_agents": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.RuleComparisonResponse": {
+            "description": "Side-by-side comparison of a custom rule and the live robots.txt of its domain. 'live_policy' is the InvalidRobotsTxt policy applied to the live file if it is an html page, json or binary data",
+            "type": "object",
+            "properties": {
+                "blocked": {
+                    "type": "boolean"
+                },
+                "diff": {
+                    "type": "string"
+                },
+                "diff_too_large": {
+                    "type": "boolean"
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "identical_verdicts": {
+                    "type": "boolean"
+                },
+                "live_policy": {
+                    "type": "string"
+                },
+                "live_status_code": {
+                    "type": "integer"
+                },
+                "rule_id": {
+                    "type": "integer"
+                },
+                "verdicts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.VerdictCompare"
+                    }
+                }
+            }
+        },
+        "model.RulePage": {
+            "description": "One page of the custom rules. 'next_cursor' is returned if there are more rules, pass it as 'cursor' to get the next page",
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Rule"
+                    }
+                }
+            }
+        },
+        "model.TdmReservationResponse": {
+            "description": "Text and Data Mining reservation (TDMRep) for the URL",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "error_code": {
+                    "description": "ErrorCode is one of the ErrorCode constants, it is set when the page can not be fetched",
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "policy": {
+                    "type": "string"
+                },
+                "reservation": {
+                    "type": "boolean"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.TokenVerdict": {
+            "description": "Verdict for one product token of the crawl purpose",
+            "type": "object",
+            "properties": {
+                "is_allowed": {
+                    "type": "boolean"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.VerdictCompare": {
+            "description": "Crawl verdicts of the custom rule and the live robots.txt for one URL and user agent",
+            "type": "object",
+            "properties": {
+                "custom_rule_allowed": {
+                    "type": "boolean"
+                },
+                "is_different": {
+                    "type": "boolean"
+                },
+                "live_allowed": {
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_agent": {
                     "type": "string"
                 }
             }
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/jimsmart/grobotstxt"
+)
+
+// PurposeAiTraining adds the ai.txt verdict to the check.
+const PurposeAiTraining = "ai-training"
+
+// checkAiTxt evaluates the ai.txt file of the url origin. The file uses the robots.txt syntax.
+// A missing file (4xx) means there is no AI-usage opt-out, so the crawl is allowed.
+func (e *Evaluator) checkAiTxt(ctx context.Context, url string, userAgent string) *model.AiTxtVerdict {
+	tResp, err := e.getAiTxt(ctx, url)
+	if err != nil {
+		errorCode, message := ClassifyFetchError(err, "ai.txt file")
+		slog.Warn("failed to get ai.txt.", slog.String("url", url), slog.String("error_code", errorCode),
+			slog.String("err", err.Error()))
+		return &model.AiTxtVerdict{
+			IsAllowed:  false,
+			Found:      false,
+			StatusCode: FetchErrorStatus(err),
+			Error:      message,
+			ErrorCode:  errorCode,
+		}
+	}
+	if tResp.StatusCode >= 400 && tResp.StatusCode < 500 {
+		return &model.AiTxtVerdict{
+			IsAllowed:  true,
+			Found:      false,
+			StatusCode: tResp.StatusCode,
+		}
+	}
+	if !isSuccess(tResp.StatusCode) {
+		errorCode, _ := statusErrorCode(tResp.StatusCode)
+		return &model.AiTxtVerdict{
+			IsAllowed:  false,
+			Found:      false,
+			StatusCode: tResp.StatusCode,
+			Error:      "ai.txt is not available",
+			ErrorCode:  errorCode,
+		}
+	}
+
+	return &model.AiTxtVerdict{
+		IsAllowed:  grobotstxt.AgentAllowed(string(tResp.Body), userAgent, url),
+		Found:      true,
+		StatusCode: tResp.StatusCode,
+	}
+}
+
+func (e *Evaluator) getAiTxt(ctx context.Context, url string) (*model.TargetResponse, error) {
+	// check if the ai.txt file is already saved in cache. An empty file marks the missing ai.txt
+	if e.cache != nil {
+		if file, ok := e.cache.GetAiTxtFile(url); ok {
+			if len(file) == 0 {
+				return &model.TargetResponse{StatusCode: http.StatusNotFound}, nil
+			}
+			return &model.TargetResponse{
+				StatusCode: http.StatusOK,
+				Body:       file,
+			}, nil
+		}
+	}
+	tResp, err := FetchFile(ctx, e.fetcher, url, "/ai.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	if e.cache == nil {
+		return tResp, nil
+	}
+	switch {
+	case isSuccess(tResp.StatusCode) && len(tResp.Body) != 0:
+		e.cache.SaveAiTxtFile(url, tResp.Body)
+	case tResp.StatusCode >= 400 && tResp.StatusCode < 500:
+		// most of the sites don't have ai.txt. Cache the absence to avoid fetching it on every check
+		slog.Debug("ai.txt not found.", slog.String("url", url), slog.Int("status_code", tResp.StatusCode))
+		e.cache.SaveAiTxtFile(url, []byte{})
+	}
+
+	return tResp, nil
+}
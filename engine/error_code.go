@@ -0,0 +1,117 @@
+package engine
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/internal/ssrf"
+	"github.com/IliaW/rule-api/util"
+)
+
+// ErrInvalidUrl is wrapped by the fetch errors of the urls that can not be requested.
+var ErrInvalidUrl = errors.New("failed to parse url")
+
+// CheckError is returned when the verdict can not be made, e.g. the request is invalid or the robots.txt file
+// can not be fetched. StatusCode is the http status of the failure.
+type CheckError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	// Err is the error of the request to the target, if any
+	Err error
+}
+
+func (e *CheckError) Error() string {
+	return e.Message
+}
+
+func (e *CheckError) Unwrap() error {
+	return e.Err
+}
+
+// verdict returns the disallowed verdict with the error.
+func (e *CheckError) verdict() Verdict {
+	return Verdict{
+		IsAllowed:  false,
+		Blocked:    false,
+		StatusCode: e.StatusCode,
+		Error:      e.Message,
+		ErrorCode:  e.Code,
+	}
+}
+
+// ClassifyFetchError maps the error of the request to the target to the error code and a short message.
+// The resource is the fetched file in the message, e.g. 'robots.txt file' or 'page'.
+// The raw error is not returned to the client, since it may contain internal details.
+func ClassifyFetchError(err error, resource string) (string, string) {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var opErr *net.OpError
+	var recordErr tls.RecordHeaderError
+	var certErr *tls.CertificateVerificationError
+	var alertErr tls.AlertError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCertErr x509.CertificateInvalidError
+
+	switch {
+	case errors.Is(err, ErrInvalidUrl):
+		return model.ErrorCodeInvalidUrl, "the url is invalid"
+	case errors.Is(err, ssrf.ErrForbiddenAddress):
+		return model.ErrorCodeForbiddenAddress, "the target address is not allowed"
+	case errors.Is(err, util.ErrBodyTooLarge):
+		return model.ErrorCodeBodyTooLarge, fmt.Sprintf("the %s is too large", resource)
+	case errors.As(err, &dnsErr):
+		return model.ErrorCodeDnsFailure, "failed to resolve the host"
+	case errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout():
+		return model.ErrorCodeTimeout, "the request to the target timed out"
+	case errors.As(err, &recordErr) || errors.As(err, &certErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidCertErr) ||
+		isTlsAlert(err):
+		return model.ErrorCodeTlsError, "the tls handshake with the target failed"
+	case errors.As(err, &opErr):
+		return model.ErrorCodeConnectionFailed, "failed to connect to the target"
+	default:
+		return model.ErrorCodeFetchFailed, fmt.Sprintf("failed to fetch the %s", resource)
+	}
+}
+
+// isTlsAlert reports whether the error is a tls alert sent or received by the tls connection. The alert type is not
+// exported, the connection wraps it in a net.OpError with the 'local error' or 'remote error' operation.
+func isTlsAlert(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && (opErr.Op == "local error" || opErr.Op == "remote error")
+}
+
+// statusErrorCode returns the error code and a short message for the non-2xx status of the robots.txt response.
+// The code is also used for the ai.txt response, the file has the robots.txt syntax.
+func statusErrorCode(statusCode int) (string, string) {
+	message := fmt.Sprintf("robots.txt returned status %d", statusCode)
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		return model.ErrorCodeRobots5xx, message
+	case statusCode >= http.StatusBadRequest:
+		return model.ErrorCodeRobots4xx, message
+	default:
+		return model.ErrorCodeFetchFailed, message
+	}
+}
+
+// FetchErrorStatus returns 400 for the invalid url, 403 if the target address is rejected by the ssrf protection and
+// 500 otherwise.
+func FetchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidUrl):
+		return http.StatusBadRequest
+	case errors.Is(err, ssrf.ErrForbiddenAddress):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
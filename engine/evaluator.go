@@ -0,0 +1,485 @@
+// Package engine makes the crawl decisions of the Rule API. The Evaluator finds the custom rule of the domain or
+// gets the robots.txt file of the target and evaluates it for the user agent. It has no dependency on the http
+// server, so other Go services can embed it:
+//
+//	fetcher := engine.NewHttpFetcher(http.DefaultClient, "my-bot", 0)
+//	evaluator := engine.New(engine.Options{}, nil, nil, fetcher)
+//	verdict, err := evaluator.Check(ctx, "https://example.com/page", "my-bot")
+//
+// The rule storage and the cache are optional. Without them the robots.txt file is fetched on every check.
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/IliaW/rule-api/config"
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/internal/persistence"
+	"github.com/IliaW/rule-api/internal/psl"
+	"github.com/IliaW/rule-api/util"
+	"github.com/jimsmart/grobotstxt"
+)
+
+// The types used by the evaluator.
+type (
+	Verdict      = model.AllowedCrawlResponse
+	TokenVerdict = model.TokenVerdict
+	AiTxtVerdict = model.AiTxtVerdict
+	Rule         = model.Rule
+	DomainAlias  = model.DomainAlias
+	RobotsFile   = model.RobotsFile
+	Response     = model.TargetResponse
+)
+
+// ErrRuleNotFound must be wrapped by the RuleStorage errors when there is no rule or alias. The fallbacks of the
+// custom rule lookup are used only for these errors.
+var ErrRuleNotFound = persistence.ErrNotFound
+
+// RuleStorage is the part of persistence.RuleStorage used to find the custom rule of the domain.
+type RuleStorage interface {
+	GetByUrl(url string) (*Rule, error)
+	GetByDomain(domain string) (*Rule, error)
+	GetAlias(alias string) (*DomainAlias, error)
+}
+
+// CachedClient is the part of cache.CachedClient used to keep the fetched robots.txt and ai.txt files.
+type CachedClient interface {
+	GetRobotsFile(url string) (*RobotsFile, bool)
+	SaveRobotsFile(url string, file *RobotsFile)
+	GetAiTxtFile(url string) ([]byte, bool)
+	SaveAiTxtFile(url string, file []byte)
+}
+
+// Options configure the evaluator. The zero value evaluates only the user agent, without the rule fallbacks.
+type Options struct {
+	// CrawlPurposes maps the purpose to the product tokens evaluated along with the user agent
+	CrawlPurposes map[string][]string
+	// WwwFallback, AliasTable and RegistrableDomain enable the fallbacks of the custom rule lookup
+	WwwFallback       bool
+	AliasTable        bool
+	RegistrableDomain bool
+	// InvalidRobotsTxt is the policy for the robots.txt files that are html pages, json or binary data.
+	// One of 'allow_all' (default), 'disallow_all' and 'parse'
+	InvalidRobotsTxt string
+	// RobotsTxtTtl is how long the fetched robots.txt file is kept in the cache. It is reported as expires_at
+	RobotsTxtTtl time.Duration
+	// Now is the clock of the evaluator. time.Now is used if nil
+	Now func() time.Time
+}
+
+// OptionsFromConfig returns the options of the Rule API config. The config may be nil.
+func OptionsFromConfig(cfg *config.Config) Options {
+	var opts Options
+	if cfg == nil {
+		return opts
+	}
+	opts.CrawlPurposes = cfg.CrawlPurposes
+	opts.InvalidRobotsTxt = cfg.InvalidRobotsTxt
+	if cfg.RuleAliasSettings != nil {
+		opts.WwwFallback = cfg.RuleAliasSettings.WwwFallback
+		opts.AliasTable = cfg.RuleAliasSettings.AliasTable
+		opts.RegistrableDomain = cfg.RuleAliasSettings.RegistrableDomain
+	}
+	if cfg.CacheSettings != nil {
+		opts.RobotsTxtTtl = cfg.CacheSettings.TtlForRobotsTxt
+	}
+	return opts
+}
+
+type Evaluator struct {
+	opts    Options
+	rules   RuleStorage
+	cache   CachedClient
+	fetcher Fetcher
+	schemes *schemeStore
+}
+
+// New creates the evaluator. The rules and the cache may be nil.
+func New(opts Options, rules RuleStorage, cache CachedClient, fetcher Fetcher) *Evaluator {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Evaluator{
+		opts:    opts,
+		rules:   rules,
+		cache:   cache,
+		fetcher: fetcher,
+		schemes: newSchemeStore(),
+	}
+}
+
+// Check checks if the user agent is allowed to crawl the url. The custom rule of the domain is used if it exists,
+// otherwise the robots.txt file is fetched from the target or the cache. The url without a scheme is tried with
+// https first and http is the fallback.
+//
+// The verdict is returned even on error. Its status code, error and error code describe the failure and the error is
+// *CheckError. A non-2xx robots.txt response is not an error: the crawl is disallowed and the verdict has the
+// ROBOTS_4XX or ROBOTS_5XX error code.
+func (e *Evaluator) Check(ctx context.Context, url string, userAgent string) (Verdict, error) {
+	return e.CheckPurpose(ctx, url, userAgent, "")
+}
+
+// CheckPurpose is Check with the crawl purpose. The product tokens of the purpose are evaluated along with the user
+// agent and 'ai-training' adds the ai.txt verdict.
+func (e *Evaluator) CheckPurpose(ctx context.Context, url string, userAgent string, purpose string) (Verdict, error) {
+	if url == "" {
+		return invalidUrl("'url' query parameter is required")
+	}
+	purposeTokens, err := e.validate(userAgent, purpose)
+	if err != nil {
+		return err.verdict(), err
+	}
+
+	url, schemeless := e.prepareUrl(url)
+	rules, err := e.resolveRobots(ctx, url, schemeless)
+	if err != nil {
+		return err.verdict(), err
+	}
+
+	return e.evaluate(ctx, rules, rules.url, userAgent, purpose, purposeTokens), nil
+}
+
+// CheckGroup checks the urls of the same origin. The robots.txt file is resolved once for the whole group.
+// The verdicts and the errors are in the order of the urls, the error is nil for the successful check.
+func (e *Evaluator) CheckGroup(ctx context.Context, urls []string, userAgent string,
+	purpose string) ([]Verdict, []error) {
+	verdicts := make([]Verdict, len(urls))
+	errs := make([]error, len(urls))
+	purposeTokens, checkErr := e.validate(userAgent, purpose)
+	if checkErr != nil {
+		for i := range verdicts {
+			verdicts[i], errs[i] = checkErr.verdict(), checkErr
+		}
+		return verdicts, errs
+	}
+
+	var rules *robotsRules
+	for i, url := range urls {
+		if url == "" {
+			verdicts[i], errs[i] = invalidUrl("'url' is required")
+			continue
+		}
+		url, schemeless := e.prepareUrl(url)
+		if rules == nil {
+			rules, checkErr = e.resolveRobots(ctx, url, schemeless)
+		}
+		if checkErr != nil {
+			verdicts[i], errs[i] = checkErr.verdict(), checkErr
+			continue
+		}
+		if schemeless {
+			// the scheme is resolved for the first url of the group
+			url = util.WithScheme(url, schemeOf(rules.url))
+		}
+		verdicts[i] = e.evaluate(ctx, rules, url, userAgent, purpose, purposeTokens)
+	}
+
+	return verdicts, errs
+}
+
+// validate returns the product tokens of the purpose, or the error if the user agent is empty or the purpose is not
+// supported.
+func (e *Evaluator) validate(userAgent string, purpose string) ([]string, *CheckError) {
+	if userAgent == "" {
+		return nil, &CheckError{
+			StatusCode: http.StatusBadRequest,
+			Code:       model.ErrorCodeInvalidRequest,
+			Message:    "'user_agent' query parameter is required",
+		}
+	}
+	purposeTokens, ok := e.opts.CrawlPurposes[purpose]
+	if purpose != "" && !ok {
+		return nil, &CheckError{
+			StatusCode: http.StatusBadRequest,
+			Code:       model.ErrorCodeInvalidRequest,
+			Message:    fmt.Sprintf("unsupported 'purpose' query parameter '%s'", purpose),
+		}
+	}
+	return purposeTokens, nil
+}
+
+// schemeMayFail reports whether the fetch error may be caused by the scheme, e.g. the host has no TLS or does not
+// listen on the port. The other errors, e.g. DNS failures, timeouts and forbidden addresses, are the same for both
+// schemes, so the other scheme is not tried.
+func schemeMayFail(err error) bool {
+	errorCode, _ := ClassifyFetchError(err, "robots.txt file")
+	return errorCode == model.ErrorCodeTlsError || errorCode == model.ErrorCodeConnectionFailed
+}
+
+func invalidUrl(message string) (Verdict, error) {
+	err := &CheckError{StatusCode: http.StatusBadRequest, Code: model.ErrorCodeInvalidUrl, Message: message}
+	return err.verdict(), err
+}
+
+// prepareUrl adds the scheme to the url without one and canonicalizes it. Returns true if the url has no scheme.
+func (e *Evaluator) prepareUrl(url string) (string, bool) {
+	// urls without a scheme use the scheme that worked for the host before or https
+	schemeless := !util.HasScheme(url)
+	if schemeless {
+		scheme := "https"
+		if domain, err := util.GetDomain("//" + strings.TrimPrefix(url, "//")); err == nil {
+			if knownScheme, ok := e.schemes.get(domain); ok {
+				scheme = knownScheme
+			}
+		}
+		url = util.WithScheme(url, scheme)
+	}
+	// the canonical url is used for the rule lookup, the cache keys and the robots.txt matching
+	if canonicalUrl, err := util.CanonicalizeUrl(url); err == nil {
+		url = canonicalUrl
+	}
+	return url, schemeless
+}
+
+// robotsRules is the robots.txt file for the origin of the url. resp has the fields of the verdict that do not
+// depend on the path and the user agent.
+type robotsRules struct {
+	url       string
+	robotsTxt string
+	resp      Verdict
+}
+
+// resolveRobots finds the custom rule or gets the robots.txt file for the origin of the url. Returns the error if
+// the robots.txt file can not be fetched.
+func (e *Evaluator) resolveRobots(ctx context.Context, url string, schemeless bool) (*robotsRules, *CheckError) {
+	rules := &robotsRules{}
+	resp := &rules.resp
+
+	// check the custom rule for the given url in database
+	rule, matchedAlias, err := e.findCustomRule(url)
+	if err == nil && rule != nil && rule.RobotsTxt != "" {
+		rules.robotsTxt = rule.RobotsTxt
+		resp.StatusCode = http.StatusOK
+		resp.Blocked = rule.Blocked
+		resp.Source = model.SourceCustomRule
+		resp.RuleId = rule.ID
+		resp.FetchedAt = timeOrNil(rule.UpdatedAt)
+	} else {
+		// upload the robots.txt file if custom rule is not found in database
+		tResp, err := e.RobotsTxt(ctx, url)
+		if err != nil && schemeless && schemeMayFail(err) {
+			// the host may not support the scheme, e.g. there is no TLS. Try the other one
+			fallbackUrl := util.WithScheme(url, fallbackScheme(url))
+			if fallbackResp, fallbackErr := e.RobotsTxt(ctx, fallbackUrl); fallbackErr == nil {
+				url, tResp, err = fallbackUrl, fallbackResp, nil
+			}
+		}
+		if err != nil {
+			// most likely, there is no access to the URL, or the robots.txt file does not exist
+			errorCode, message := ClassifyFetchError(err, "robots.txt file")
+			slog.Warn("failed to get robots.txt.", slog.String("url", url), slog.String("error_code", errorCode),
+				slog.String("err", err.Error()))
+			return nil, &CheckError{StatusCode: FetchErrorStatus(err), Code: errorCode, Message: message, Err: err}
+		}
+		if schemeless {
+			if domain, err := util.GetDomain(url); err == nil {
+				e.schemes.set(domain, schemeOf(url))
+			}
+		}
+		rules.robotsTxt = string(tResp.Body)
+		resp.StatusCode = tResp.StatusCode
+		resp.Content = tResp.Content
+		resp.Source = tResp.Source
+		resp.RobotsUrl = tResp.Url
+		resp.FetchedAt = timeOrNil(tResp.FetchedAt)
+		resp.ExpiresAt = timeOrNil(tResp.ExpiresAt)
+	}
+	rules.url = url
+	resp.MatchedAlias = matchedAlias
+	if schemeless {
+		resp.ResolvedOrigin, _ = util.GetBaseUrl(url)
+	}
+	if domain, err := util.GetDomain(url); err == nil {
+		resp.RegistrableDomain, _ = psl.RegistrableDomain(domain)
+	}
+
+	return rules, nil
+}
+
+// evaluate makes the verdict for the url and the user agent by the resolved robots.txt file.
+func (e *Evaluator) evaluate(ctx context.Context, rules *robotsRules, url string, userAgent string, purpose string,
+	purposeTokens []string) Verdict {
+	resp := rules.resp
+	if purpose == PurposeAiTraining {
+		resp.AiTxt = e.checkAiTxt(ctx, url, userAgent)
+	}
+
+	switch {
+	case !isSuccess(resp.StatusCode):
+		// the body of the error page is not returned
+		resp.IsAllowed = false
+		resp.ErrorCode, resp.Error = statusErrorCode(resp.StatusCode)
+	case purpose == "":
+		// without a purpose only the user agent is evaluated
+		resp.IsAllowed = grobotstxt.AgentAllowed(rules.robotsTxt, userAgent, url)
+	default:
+		// the crawl is allowed only if the user agent and every product token of the purpose are allowed
+		resp.IsAllowed = true
+		resp.TokenVerdicts = make([]TokenVerdict, 0, len(purposeTokens)+1)
+		for _, token := range append([]string{userAgent}, purposeTokens...) {
+			if slices.ContainsFunc(resp.TokenVerdicts, func(v TokenVerdict) bool {
+				return strings.EqualFold(v.Token, token)
+			}) {
+				continue
+			}
+			allowed := grobotstxt.AgentAllowed(rules.robotsTxt, token, url)
+			resp.IsAllowed = resp.IsAllowed && allowed
+			resp.TokenVerdicts = append(resp.TokenVerdicts, TokenVerdict{Token: token, IsAllowed: allowed})
+		}
+	}
+
+	return resp
+}
+
+// findCustomRule looks up the custom rule for the url domain. If there is no rule for the exact domain, the www,
+// the alias table and the registrable domain fallbacks are used if enabled. The matched alias is the domain of
+// the rule found by a fallback. The fallbacks are used only if the rule is not found, any other error, e.g. of the
+// database, is returned as is.
+func (e *Evaluator) findCustomRule(url string) (*Rule, string, error) {
+	if e.rules == nil {
+		return nil, "", nil
+	}
+	rule, err := e.rules.GetByUrl(url)
+	if !errors.Is(err, ErrRuleNotFound) || !e.opts.WwwFallback && !e.opts.AliasTable && !e.opts.RegistrableDomain {
+		return rule, "", err
+	}
+	domain, parseErr := util.GetDomain(url)
+	if parseErr != nil {
+		return nil, "", err
+	}
+
+	if e.opts.WwwFallback {
+		alias := "www." + domain
+		if strings.HasPrefix(domain, "www.") {
+			alias = strings.TrimPrefix(domain, "www.")
+		}
+		aliasRule, aliasErr := e.rules.GetByDomain(alias)
+		if aliasErr == nil {
+			return aliasRule, aliasRule.Domain, nil
+		}
+		if !errors.Is(aliasErr, ErrRuleNotFound) {
+			return nil, "", aliasErr
+		}
+	}
+
+	if e.opts.AliasTable {
+		domainAlias, aliasErr := e.rules.GetAlias(domain)
+		if aliasErr == nil {
+			aliasRule, ruleErr := e.rules.GetByDomain(domainAlias.Domain)
+			if ruleErr == nil {
+				return aliasRule, aliasRule.Domain, nil
+			}
+			aliasErr = ruleErr
+		}
+		if !errors.Is(aliasErr, ErrRuleNotFound) {
+			return nil, "", aliasErr
+		}
+	}
+
+	if e.opts.RegistrableDomain {
+		// the subdomains share the rule of the registrable domain, e.g. 'a.example.co.uk' -> 'example.co.uk'
+		if registrable, regErr := psl.RegistrableDomain(domain); regErr == nil && registrable != domain {
+			aliasRule, ruleErr := e.rules.GetByDomain(registrable)
+			if ruleErr == nil {
+				return aliasRule, aliasRule.Domain, nil
+			}
+			if !errors.Is(ruleErr, ErrRuleNotFound) {
+				return nil, "", ruleErr
+			}
+		}
+	}
+
+	return nil, "", err
+}
+
+// RobotsTxt gets the robots.txt file for the origin of the url from the cache or the target. The fetched file is
+// decoded to UTF-8 and cached if the response is successful.
+func (e *Evaluator) RobotsTxt(ctx context.Context, url string) (*Response, error) {
+	// check if the robots.txt file is already saved in cache
+	if e.cache != nil {
+		if file, ok := e.cache.GetRobotsFile(url); ok {
+			return &Response{
+				StatusCode: file.StatusCode,
+				Body:       file.Body,
+				Content:    file.Content,
+				Url:        file.Url,
+				Source:     model.SourceCache,
+				FetchedAt:  file.FetchedAt,
+				ExpiresAt:  file.ExpiresAt,
+			}, nil
+		}
+	}
+	// make get request to fetch the robots.txt file if it is not saved in cache
+	tResp, err := e.fetchRobotsTxt(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	// save the robots.txt file to cache if the request is successful and the body is not empty
+	if e.cache != nil && isSuccess(tResp.StatusCode) && len(tResp.Body) != 0 {
+		if e.opts.RobotsTxtTtl > 0 {
+			tResp.ExpiresAt = tResp.FetchedAt.Add(e.opts.RobotsTxtTtl)
+		}
+		e.cache.SaveRobotsFile(url, &RobotsFile{
+			StatusCode:   tResp.StatusCode,
+			Url:          tResp.Url,
+			Body:         tResp.Body,
+			Content:      tResp.Content,
+			FetchedAt:    tResp.FetchedAt,
+			ExpiresAt:    tResp.ExpiresAt,
+			ETag:         tResp.Header.Get("ETag"),
+			LastModified: tResp.Header.Get("Last-Modified"),
+		})
+	}
+
+	return tResp, nil
+}
+
+// LiveRobotsTxt fetches the robots.txt file for the origin of the url from the target. The cache is neither read nor
+// updated. The file is decoded and the invalid file is replaced by the InvalidRobotsTxt policy like in the checks,
+// and the url without a scheme falls back to the other scheme.
+func (e *Evaluator) LiveRobotsTxt(ctx context.Context, url string) (*Response, error) {
+	url, schemeless := e.prepareUrl(url)
+	tResp, err := e.fetchRobotsTxt(ctx, url)
+	if err != nil && schemeless && schemeMayFail(err) {
+		// the host may not support the scheme, e.g. there is no TLS. Try the other one
+		fallbackUrl := util.WithScheme(url, fallbackScheme(url))
+		if fallbackResp, fallbackErr := e.fetchRobotsTxt(ctx, fallbackUrl); fallbackErr == nil {
+			tResp, err = fallbackResp, nil
+		}
+	}
+
+	return tResp, err
+}
+
+func (e *Evaluator) fetchRobotsTxt(ctx context.Context, url string) (*Response, error) {
+	tResp, err := FetchFile(ctx, e.fetcher, url, "/robots.txt")
+	if err != nil {
+		return nil, err
+	}
+	if isSuccess(tResp.StatusCode) {
+		e.decodeRobotsTxt(tResp)
+	}
+	tResp.Source = model.SourceLive
+	tResp.FetchedAt = e.opts.Now().UTC()
+
+	return tResp, nil
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode >= 200 && statusCode < 300
+}
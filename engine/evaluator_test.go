@@ -0,0 +1,363 @@
+package engine
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/internal/ssrf"
+	"github.com/IliaW/rule-api/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTime = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeFetcher serves the robots.txt files by url and records the requested urls.
+type fakeFetcher struct {
+	files     map[string]string
+	requested []string
+}
+
+func (f *fakeFetcher) Fetch(_ context.Context, _ string, url string, _ int64) (*Response, error) {
+	f.requested = append(f.requested, url)
+	switch {
+	case strings.Contains(url, "dns-error"):
+		return nil, &net.DNSError{Err: "no such host", Name: "dns-error.com", IsNotFound: true}
+	case strings.Contains(url, "timeout"):
+		return nil, fmt.Errorf("Get \"%s\": %w", url, context.DeadlineExceeded)
+	}
+	if strings.HasSuffix(url, "/ai.txt") && strings.Contains(url, "ai-txt-unavailable") {
+		return &Response{StatusCode: http.StatusServiceUnavailable, Url: url}, nil
+	}
+	if strings.HasPrefix(url, "https://") && strings.Contains(url, "http-only") ||
+		strings.HasSuffix(url, "/ai.txt") && strings.Contains(url, "ai-txt-error") {
+		return nil, &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	}
+	body, ok := f.files[url]
+	if !ok {
+		return &Response{StatusCode: http.StatusNotFound, Url: url}, nil
+	}
+	return &Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/plain"}},
+		Body:       []byte(body),
+		Url:        url,
+	}, nil
+}
+
+// fakeRules serves the custom rules by domain. The lookups of the 'db-error.' domains fail.
+type fakeRules map[string]*Rule
+
+func (r fakeRules) GetByUrl(url string) (*Rule, error) {
+	domain, err := util.GetDomain(url)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByDomain(domain)
+}
+
+func (r fakeRules) GetByDomain(domain string) (*Rule, error) {
+	if strings.HasPrefix(domain, "db-error.") {
+		return nil, errors.New("connection refused")
+	}
+	if rule, ok := r[domain]; ok {
+		return rule, nil
+	}
+	return nil, fmt.Errorf("rule with domain '%s' %w", domain, ErrRuleNotFound)
+}
+
+func (r fakeRules) GetAlias(alias string) (*DomainAlias, error) {
+	return nil, fmt.Errorf("alias '%s' %w", alias, ErrRuleNotFound)
+}
+
+func Test_Evaluator_Check(t *testing.T) {
+	fetcher := &fakeFetcher{files: map[string]string{
+		"https://example.com/robots.txt":            "User-agent: *\nDisallow: /private\n\nUser-agent: GPTBot\nDisallow: /",
+		"https://ai-txt-error.com/robots.txt":       "User-agent: *\nAllow: /",
+		"https://ai-txt-unavailable.com/robots.txt": "User-agent: *\nAllow: /",
+	}}
+	rules := fakeRules{"blocked.com": {ID: 7, Domain: "blocked.com", RobotsTxt: "User-agent: *\nDisallow: /",
+		Blocked: true}}
+	evaluator := New(Options{
+		CrawlPurposes: map[string][]string{"ai-search": {"GPTBot"}, PurposeAiTraining: {}},
+		Now:           func() time.Time { return testTime },
+	}, rules, nil, fetcher)
+
+	testSet := []struct {
+		name      string
+		url       string
+		userAgent string
+		purpose   string
+		expected  string
+		errorCode string
+	}{
+		{
+			name:      "allowed path",
+			url:       "https://example.com/public",
+			userAgent: "bot",
+			expected: "{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"registrable_domain\":\"example.com\",\"content\":{\"content_type\":\"text/plain\"," +
+				"\"charset\":\"utf-8\",\"bom\":false,\"detected\":\"robots.txt\",\"valid\":true},\"source\":\"live\"," +
+				"\"robots_url\":\"https://example.com/robots.txt\",\"fetched_at\":\"2025-01-01T12:00:00Z\"}",
+		},
+		{
+			name:      "purpose token disallowed",
+			url:       "https://example.com/public",
+			userAgent: "bot",
+			purpose:   "ai-search",
+			expected: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"token_verdicts\":[{\"token\":\"bot\",\"is_allowed\":true},{\"token\":\"GPTBot\",\"is_allowed\":false}]," +
+				"\"registrable_domain\":\"example.com\",\"content\":{\"content_type\":\"text/plain\"," +
+				"\"charset\":\"utf-8\",\"bom\":false,\"detected\":\"robots.txt\",\"valid\":true},\"source\":\"live\"," +
+				"\"robots_url\":\"https://example.com/robots.txt\",\"fetched_at\":\"2025-01-01T12:00:00Z\"}",
+		},
+		{
+			name:      "ai.txt fetch error",
+			url:       "https://ai-txt-error.com/page",
+			userAgent: "bot",
+			purpose:   PurposeAiTraining,
+			expected: "{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"ai_txt\":{\"is_allowed\":false,\"found\":false,\"status_code\":500," +
+				"\"error\":\"failed to connect to the target\",\"error_code\":\"CONNECTION_FAILED\"}," +
+				"\"token_verdicts\":[{\"token\":\"bot\",\"is_allowed\":true}]," +
+				"\"registrable_domain\":\"ai-txt-error.com\",\"content\":{\"content_type\":\"text/plain\"," +
+				"\"charset\":\"utf-8\",\"bom\":false,\"detected\":\"robots.txt\",\"valid\":true},\"source\":\"live\"," +
+				"\"robots_url\":\"https://ai-txt-error.com/robots.txt\",\"fetched_at\":\"2025-01-01T12:00:00Z\"}",
+		},
+		{
+			name:      "ai.txt server error",
+			url:       "https://ai-txt-unavailable.com/page",
+			userAgent: "bot",
+			purpose:   PurposeAiTraining,
+			expected: "{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"ai_txt\":{\"is_allowed\":false,\"found\":false,\"status_code\":503," +
+				"\"error\":\"ai.txt is not available\",\"error_code\":\"ROBOTS_5XX\"}," +
+				"\"token_verdicts\":[{\"token\":\"bot\",\"is_allowed\":true}]," +
+				"\"registrable_domain\":\"ai-txt-unavailable.com\",\"content\":{\"content_type\":\"text/plain\"," +
+				"\"charset\":\"utf-8\",\"bom\":false,\"detected\":\"robots.txt\",\"valid\":true},\"source\":\"live\"," +
+				"\"robots_url\":\"https://ai-txt-unavailable.com/robots.txt\",\"fetched_at\":\"2025-01-01T12:00:00Z\"}",
+		},
+		{
+			name:      "custom rule",
+			url:       "https://blocked.com/page",
+			userAgent: "bot",
+			expected: "{\"is_allowed\":false,\"blocked\":true,\"status_code\":200,\"error\":\"\"," +
+				"\"registrable_domain\":\"blocked.com\",\"source\":\"custom_rule\",\"rule_id\":7}",
+		},
+		{
+			name:      "missing robots.txt",
+			url:       "https://missing.com/page",
+			userAgent: "bot",
+			expected: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":404," +
+				"\"error\":\"robots.txt returned status 404\",\"error_code\":\"ROBOTS_4XX\"," +
+				"\"registrable_domain\":\"missing.com\",\"source\":\"live\"," +
+				"\"robots_url\":\"https://missing.com/robots.txt\",\"fetched_at\":\"2025-01-01T12:00:00Z\"}",
+		},
+		{
+			name:      "unsupported purpose",
+			url:       "https://example.com/public",
+			userAgent: "bot",
+			purpose:   "unknown",
+			expected: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":400," +
+				"\"error\":\"unsupported 'purpose' query parameter 'unknown'\",\"error_code\":\"INVALID_REQUEST\"}",
+			errorCode: model.ErrorCodeInvalidRequest,
+		},
+		{
+			name:      "fetch error",
+			url:       "https://http-only.com/page",
+			userAgent: "bot",
+			expected: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":500," +
+				"\"error\":\"failed to connect to the target\",\"error_code\":\"CONNECTION_FAILED\"}",
+			errorCode: model.ErrorCodeConnectionFailed,
+		},
+	}
+
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			verdict, err := evaluator.CheckPurpose(context.Background(), test.url, test.userAgent, test.purpose)
+
+			responseData, _ := json.Marshal(verdict)
+			assert.Equal(tt, test.expected, string(responseData))
+			if test.errorCode == "" {
+				assert.NoError(tt, err)
+				return
+			}
+			var checkErr *CheckError
+			assert.ErrorAs(tt, err, &checkErr)
+			assert.Equal(tt, test.errorCode, checkErr.Code)
+			assert.Equal(tt, verdict.StatusCode, checkErr.StatusCode)
+		})
+	}
+}
+
+func Test_Evaluator_RuleFallback(t *testing.T) {
+	fetcher := &fakeFetcher{files: map[string]string{
+		"https://db-error.example.com/robots.txt": "User-agent: *\nAllow: /",
+	}}
+	rules := fakeRules{"example.com": {ID: 1, Domain: "example.com", RobotsTxt: "User-agent: *\nDisallow: /"}}
+	evaluator := New(Options{RegistrableDomain: true}, rules, nil, fetcher)
+
+	verdict, err := evaluator.Check(context.Background(), "https://blog.example.com/page", "bot")
+	assert.NoError(t, err)
+	assert.False(t, verdict.IsAllowed)
+	assert.Equal(t, model.SourceCustomRule, verdict.Source)
+	assert.Equal(t, "example.com", verdict.MatchedAlias)
+
+	// the rule of the registrable domain is not used if the lookup fails, the live robots.txt is evaluated instead
+	verdict, err = evaluator.Check(context.Background(), "https://db-error.example.com/page", "bot")
+	assert.NoError(t, err)
+	assert.True(t, verdict.IsAllowed)
+	assert.Equal(t, model.SourceLive, verdict.Source)
+	assert.Empty(t, verdict.MatchedAlias)
+}
+
+func Test_Evaluator_SchemelessUrl(t *testing.T) {
+	fetcher := &fakeFetcher{files: map[string]string{"http://http-only.com/robots.txt": "User-agent: *\nAllow: /"}}
+	evaluator := New(Options{}, nil, nil, fetcher)
+
+	for range 2 {
+		verdict, err := evaluator.Check(context.Background(), "http-only.com/page", "bot")
+		assert.NoError(t, err)
+		assert.True(t, verdict.IsAllowed)
+		assert.Equal(t, "http://http-only.com", verdict.ResolvedOrigin)
+	}
+	// the scheme that worked is remembered for the host, so https is not tried again
+	assert.Equal(t, []string{"https://http-only.com/robots.txt", "http://http-only.com/robots.txt",
+		"http://http-only.com/robots.txt"}, fetcher.requested)
+}
+
+func Test_Evaluator_LiveRobotsTxt(t *testing.T) {
+	fetcher := &fakeFetcher{files: map[string]string{
+		"http://http-only.com/robots.txt": "<html><body>Not found</body></html>"}}
+	evaluator := New(Options{InvalidRobotsTxt: policyDisallowAll}, nil, nil, fetcher)
+
+	resp, err := evaluator.LiveRobotsTxt(context.Background(), "http-only.com")
+	assert.NoError(t, err)
+	// the invalid file is replaced by the policy like in the checks
+	assert.Equal(t, disallowAllRobotsTxt, string(resp.Body))
+	assert.False(t, resp.Content.Valid)
+	assert.Equal(t, policyDisallowAll, resp.Content.Policy)
+	assert.Equal(t, model.SourceLive, resp.Source)
+	assert.Equal(t, []string{"https://http-only.com/robots.txt", "http://http-only.com/robots.txt"},
+		fetcher.requested)
+}
+
+func Test_Evaluator_SchemelessUrl_NoFallback(t *testing.T) {
+	tests := []struct {
+		url       string
+		errorCode string
+	}{
+		{url: "dns-error.com/page", errorCode: model.ErrorCodeDnsFailure},
+		{url: "timeout.com/page", errorCode: model.ErrorCodeTimeout},
+	}
+
+	for _, test := range tests {
+		t.Run(test.errorCode, func(tt *testing.T) {
+			fetcher := &fakeFetcher{}
+			evaluator := New(Options{}, nil, nil, fetcher)
+
+			verdict, err := evaluator.Check(context.Background(), test.url, "bot")
+			assert.Error(tt, err)
+			assert.Equal(tt, test.errorCode, verdict.ErrorCode)
+			// the error does not depend on the scheme, so http is not tried after https
+			assert.Len(tt, fetcher.requested, 1)
+		})
+	}
+}
+
+func Test_ClassifyFetchError(t *testing.T) {
+	tests := []struct {
+		err        error
+		expected   string
+		statusCode int
+		message    string
+	}{
+		{
+			err:        fmt.Errorf("%w. missing host", ErrInvalidUrl),
+			expected:   model.ErrorCodeInvalidUrl,
+			statusCode: http.StatusBadRequest,
+			message:    "the url is invalid",
+		},
+		{
+			err:        &url.Error{Op: "Get", Err: ssrf.ErrForbiddenAddress},
+			expected:   model.ErrorCodeForbiddenAddress,
+			statusCode: http.StatusForbidden,
+			message:    "the target address is not allowed",
+		},
+		{
+			err:        fmt.Errorf("%w. The limit is 10 bytes", util.ErrBodyTooLarge),
+			expected:   model.ErrorCodeBodyTooLarge,
+			statusCode: http.StatusInternalServerError,
+			message:    "the ai.txt file is too large",
+		},
+		{
+			err:        &url.Error{Op: "Get", Err: &net.DNSError{Err: "no such host", Name: "example.invalid"}},
+			expected:   model.ErrorCodeDnsFailure,
+			statusCode: http.StatusInternalServerError,
+			message:    "failed to resolve the host",
+		},
+		{
+			err:        &url.Error{Op: "Get", Err: context.DeadlineExceeded},
+			expected:   model.ErrorCodeTimeout,
+			statusCode: http.StatusInternalServerError,
+			message:    "the request to the target timed out",
+		},
+		{
+			err:        &url.Error{Op: "Get", Err: x509.UnknownAuthorityError{}},
+			expected:   model.ErrorCodeTlsError,
+			statusCode: http.StatusInternalServerError,
+			message:    "the tls handshake with the target failed",
+		},
+		{
+			err: &url.Error{Op: "Get",
+				Err: tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}},
+			expected:   model.ErrorCodeTlsError,
+			statusCode: http.StatusInternalServerError,
+			message:    "the tls handshake with the target failed",
+		},
+		{
+			err:        &url.Error{Op: "Get", Err: &net.OpError{Op: "remote error", Err: tls.AlertError(40)}},
+			expected:   model.ErrorCodeTlsError,
+			statusCode: http.StatusInternalServerError,
+			message:    "the tls handshake with the target failed",
+		},
+		{
+			// the error text is not matched, only the error types
+			err:        &url.Error{Op: "Get", URL: "https://example.com/?q=tls:", Err: errors.New("unexpected EOF")},
+			expected:   model.ErrorCodeFetchFailed,
+			statusCode: http.StatusInternalServerError,
+			message:    "failed to fetch the ai.txt file",
+		},
+		{
+			err:        &url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+			expected:   model.ErrorCodeConnectionFailed,
+			statusCode: http.StatusInternalServerError,
+			message:    "failed to connect to the target",
+		},
+		{
+			err:        errors.New("unexpected EOF"),
+			expected:   model.ErrorCodeFetchFailed,
+			statusCode: http.StatusInternalServerError,
+			message:    "failed to fetch the ai.txt file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(tt *testing.T) {
+			code, message := ClassifyFetchError(test.err, "ai.txt file")
+			assert.Equal(tt, test.expected, code)
+			assert.Equal(tt, test.message, message)
+			assert.Equal(tt, test.statusCode, FetchErrorStatus(test.err))
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/IliaW/rule-api/util"
+)
+
+const defaultMaxResponseSize = 512 * 1024
+
+// Fetcher makes the requests to the targets. HttpFetcher is the default implementation.
+type Fetcher interface {
+	// Fetch requests the url. The body is decompressed according to the Content-Encoding header. If maxBodySize is
+	// greater than zero, the body is truncated to it. Otherwise, the body must not exceed the limit of the fetcher.
+	// The invalid url error wraps ErrInvalidUrl.
+	Fetch(ctx context.Context, method string, url string, maxBodySize int64) (*Response, error)
+}
+
+type HttpFetcher struct {
+	httpClient      *http.Client
+	userAgent       string
+	maxResponseSize int64
+}
+
+// NewHttpFetcher creates the fetcher that sends the requests with the user agent. The responses larger than
+// maxResponseSize bytes are rejected, 512KB is used if it is not positive.
+func NewHttpFetcher(httpClient *http.Client, userAgent string, maxResponseSize int64) *HttpFetcher {
+	if maxResponseSize <= 0 {
+		maxResponseSize = defaultMaxResponseSize
+	}
+	return &HttpFetcher{
+		httpClient:      httpClient,
+		userAgent:       userAgent,
+		maxResponseSize: maxResponseSize,
+	}
+}
+
+func (f *HttpFetcher) Fetch(ctx context.Context, method string, target string, maxBodySize int64) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w. %s", ErrInvalidUrl, err.Error())
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	// the explicit header disables the transparent gzip decoding of the transport, so every encoding is limited
+	req.Header.Set("Accept-Encoding", util.AcceptEncoding)
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		slog.Error(fmt.Sprintf("error making http %s request to %s", strings.ToLower(method), target),
+			slog.String("err", err.Error()))
+		return nil, err
+	}
+	defer func() {
+		err = resp.Body.Close()
+		if err != nil {
+			slog.Error("error closing response body", slog.String("err", err.Error()))
+		}
+	}()
+
+	contentEncoding := resp.Header.Get("Content-Encoding")
+	buffered := bufio.NewReader(resp.Body)
+	var reader io.Reader = buffered
+	// the responses without a body keep the Content-Encoding of the full response, there is nothing to decode
+	if hasBody(req.Method, resp, buffered) {
+		reader, err = util.Decompress(reader, contentEncoding)
+		if err != nil {
+			slog.Error("error decompressing response body", slog.String("url", target),
+				slog.String("err", err.Error()))
+			return nil, err
+		}
+	}
+	var body []byte
+	if maxBodySize > 0 {
+		body, err = io.ReadAll(io.LimitReader(reader, maxBodySize))
+	} else {
+		body, err = util.ReadAllLimit(reader, f.maxResponseSize)
+	}
+	if err != nil {
+		slog.Error("error reading response body", slog.String("url", target), slog.String("err", err.Error()))
+		return nil, err
+	}
+	header := resp.Header.Clone()
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+
+	finalUrl := target
+	if resp.Request != nil && resp.Request.URL != nil {
+		finalUrl = resp.Request.URL.String() // the url after redirects
+	}
+
+	return &Response{
+		StatusCode:      resp.StatusCode,
+		Header:          header,
+		Body:            body,
+		ContentEncoding: contentEncoding,
+		Url:             finalUrl,
+	}, nil
+}
+
+// hasBody reports whether the response has a body. The HEAD responses and the 204 and 304 responses never have it.
+func hasBody(method string, resp *http.Response, body *bufio.Reader) bool {
+	if method == http.MethodHead || resp.StatusCode == http.StatusNoContent ||
+		resp.StatusCode == http.StatusNotModified || resp.ContentLength == 0 {
+		return false
+	}
+	_, err := body.Peek(1)
+
+	return err == nil
+}
+
+// FetchFile fetches the file with the given path, e.g. '/robots.txt', from the origin of the url.
+func FetchFile(ctx context.Context, fetcher Fetcher, url string, path string) (*Response, error) {
+	baseUrl, err := util.GetBaseUrl(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w. %s", ErrInvalidUrl, err.Error())
+	}
+
+	return fetcher.Fetch(ctx, http.MethodGet, baseUrl+path, 0)
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HttpFetcher_Fetch(t *testing.T) {
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	_, _ = w.Write([]byte("User-agent: *\nDisallow: /"))
+	_ = w.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		switch r.URL.Path {
+		case "/not-modified":
+			w.WriteHeader(http.StatusNotModified)
+		case "/no-content":
+			w.WriteHeader(http.StatusNoContent)
+		case "/empty":
+			w.WriteHeader(http.StatusOK)
+		default:
+			_, _ = w.Write(compressed.Bytes())
+		}
+	}))
+	defer server.Close()
+	fetcher := NewHttpFetcher(server.Client(), "bot", 0)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		statusCode int
+		body       string
+	}{
+		{name: "gzip", method: http.MethodGet, path: "/robots.txt", statusCode: http.StatusOK,
+			body: "User-agent: *\nDisallow: /"},
+		{name: "head with gzip", method: http.MethodHead, path: "/robots.txt", statusCode: http.StatusOK},
+		{name: "not modified", method: http.MethodGet, path: "/not-modified", statusCode: http.StatusNotModified},
+		{name: "no content", method: http.MethodGet, path: "/no-content", statusCode: http.StatusNoContent},
+		{name: "empty body", method: http.MethodGet, path: "/empty", statusCode: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			resp, err := fetcher.Fetch(context.Background(), test.method, server.URL+test.path, 0)
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.statusCode, resp.StatusCode)
+			assert.Equal(tt, test.body, string(resp.Body))
+			assert.Equal(tt, "gzip", resp.ContentEncoding)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"log/slog"
+
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/util"
+)
+
+const (
+	policyAllowAll    = "allow_all"
+	policyDisallowAll = "disallow_all"
+	policyParse       = "parse"
+
+	// the explicit file is used instead of an empty one, so the result can be cached
+	allowAllRobotsTxt    = "User-agent: *\nAllow: /\n"
+	disallowAllRobotsTxt = "User-agent: *\nDisallow: /\n"
+)
+
+// decodeRobotsTxt converts the fetched robots.txt file to UTF-8 and replaces it according to the InvalidRobotsTxt
+// policy if it is an html page, json or binary data.
+func (e *Evaluator) decodeRobotsTxt(tResp *model.TargetResponse) {
+	contentType := tResp.Header.Get("Content-Type")
+	body, charset, bom, err := util.DecodeText(tResp.Body, contentType)
+	if err != nil {
+		slog.Warn("failed to decode robots.txt.", slog.String("content_type", contentType),
+			slog.String("err", err.Error()))
+	}
+	content := &model.RobotsContent{
+		ContentType:     contentType,
+		ContentEncoding: tResp.ContentEncoding,
+		Charset:         charset,
+		Bom:             bom,
+		Detected:        util.DetectContent(body),
+	}
+	content.Valid = content.Detected == util.ContentRobotsTxt
+	if !content.Valid {
+		content.Policy = e.invalidRobotsTxtPolicy()
+		switch content.Policy {
+		case policyAllowAll:
+			body = []byte(allowAllRobotsTxt)
+		case policyDisallowAll:
+			body = []byte(disallowAllRobotsTxt)
+		}
+	}
+	tResp.Body = body
+	tResp.Content = content
+}
+
+func (e *Evaluator) invalidRobotsTxtPolicy() string {
+	switch e.opts.InvalidRobotsTxt {
+	case policyDisallowAll, policyParse:
+		return e.opts.InvalidRobotsTxt
+	default:
+		return policyAllowAll
+	}
+}
@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"strings"
+	"sync"
+)
+
+// maxRememberedSchemes limits the memory used by the scheme store. The store is reset when the limit is reached.
+const maxRememberedSchemes = 100_000
+
+// schemeStore remembers which scheme worked for the host when the url was sent without a scheme.
+type schemeStore struct {
+	mu      sync.RWMutex
+	schemes map[string]string
+}
+
+func newSchemeStore() *schemeStore {
+	return &schemeStore{schemes: make(map[string]string)}
+}
+
+func (s *schemeStore) get(host string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	scheme, ok := s.schemes[host]
+	return scheme, ok
+}
+
+func (s *schemeStore) set(host string, scheme string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.schemes) >= maxRememberedSchemes {
+		s.schemes = make(map[string]string)
+	}
+	s.schemes[host] = scheme
+}
+
+func schemeOf(url string) string {
+	scheme, _, _ := strings.Cut(url, "://")
+	return strings.ToLower(scheme)
+}
+
+// fallbackScheme returns the scheme to try if the host is not reachable with the scheme of the url.
+func fallbackScheme(url string) string {
+	if schemeOf(url) == "https" {
+		return "http"
+	}
+	return "https"
+}
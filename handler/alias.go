@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/util"
+	"github.com/gin-gonic/gin"
+)
+
+// GetDomainAliases godoc
+// @Summary Get domain aliases
+// @Description Retrieve all domain aliases or the alias provided in the 'alias' query parameter
+// @Tags Domain Alias
+// @Produce json
+// @Param alias query string false "Alias domain"
+// @Success 200 {array} model.DomainAlias "Domain aliases"
+// @Failure 404 {object} model.ErrorResponse "Alias not found"
+// @Failure 500 {object} model.ErrorResponse "Database error"
+// @Failure 401 {object} model.ErrorResponse "Missing or invalid API key"
+// @Failure 403 {object} model.ErrorResponse "API key is not active"
+// @Security ApiKeyAuth
+// @Router /domain-alias [get]
+func (h *RuleApiHandler) GetDomainAliases(c *gin.Context) {
+	alias := c.Query("alias")
+	if alias == "" {
+		aliases, err := h.ruleRepo.GetAliases()
+		if err != nil {
+			AbortWithError(c, storageErrorStatus(err), "failed to get aliases", err)
+			return
+		}
+		c.JSON(http.StatusOK, aliases)
+		return
+	}
+
+	domainAlias, err := h.ruleRepo.GetAlias(canonicalHostOrRaw(alias))
+	if err != nil {
+		AbortWithError(c, storageErrorStatus(err), "failed to get alias", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, []*model.DomainAlias{domainAlias})
+}
+
+// CreateDomainAlias godoc
+// @Summary Create a domain alias
+// @Description Create an alias, so the custom rule of the domain is used for the alias domain
+// @Tags Domain Alias
+// @Produce json
+// @Param alias query string true "Alias domain, e.g. example.co.uk"
+// @Param domain query string true "Domain of the custom rule, e.g. example.com"
+// @Success 200 {object} string "Alias created successfully"
+// @Failure 400 {object} model.ErrorResponse "Missing query parameter"
+// @Failure 409 {object} model.ErrorResponse "Alias already exists"
+// @Failure 422 {object} model.ErrorResponse "Invalid or too long domain, or the alias is the same as the domain"
+// @Failure 500 {object} model.ErrorResponse "Database error"
+// @Failure 401 {object} model.ErrorResponse "Missing or invalid API key"
+// @Failure 403 {object} model.ErrorResponse "API key is not active"
+// @Security ApiKeyAuth
+// @Router /domain-alias [post]
+func (h *RuleApiHandler) CreateDomainAlias(c *gin.Context) {
+	alias := c.Query("alias")
+	domain := c.Query("domain")
+	if alias == "" || domain == "" {
+		AbortWithError(c, http.StatusBadRequest, "'alias' and 'domain' query parameters are required", nil)
+		return
+	}
+	if canonicalHostOrRaw(alias) == canonicalHostOrRaw(domain) {
+		AbortWithError(c, http.StatusUnprocessableEntity, "'alias' and 'domain' must be different", nil)
+		return
+	}
+	if len(canonicalHostOrRaw(alias)) > maxDomainLength || len(canonicalHostOrRaw(domain)) > maxDomainLength {
+		AbortWithError(c, http.StatusUnprocessableEntity,
+			fmt.Sprintf("'alias' and 'domain' must not be longer than %d characters", maxDomainLength), nil)
+		return
+	}
+
+	id, err := h.ruleRepo.SaveAlias(&model.DomainAlias{
+		Alias:  alias,
+		Domain: domain,
+	})
+	if err != nil {
+		AbortWithError(c, storageErrorStatus(err), "failed to save alias", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+// DeleteDomainAlias godoc
+// @Summary Delete a domain alias by ID
+// @Description Delete an existing domain alias based on the provided ID.
+// @Tags Domain Alias
+// @Produce json
+// @Param id query string true "Alias ID"
+// @Success 200 {object} string "Alias deleted successfully"
+// @Failure 400 {object} model.ErrorResponse "Missing or invalid query parameter"
+// @Failure 404 {object} model.ErrorResponse "Alias not found"
+// @Failure 500 {object} model.ErrorResponse "Database error"
+// @Failure 401 {object} model.ErrorResponse "Missing or invalid API key"
+// @Failure 403 {object} model.ErrorResponse "API key is not active"
+// @Security ApiKeyAuth
+// @Router /domain-alias [delete]
+func (h *RuleApiHandler) DeleteDomainAlias(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		AbortWithError(c, http.StatusBadRequest, "'id' query parameter is required", nil)
+		return
+	}
+	if !isValidId(id) {
+		AbortWithError(c, http.StatusBadRequest, "'id' query parameter must be an integer", nil)
+		return
+	}
+
+	err := h.ruleRepo.DeleteAlias(id)
+	if err != nil {
+		AbortWithError(c, storageErrorStatus(err), "failed to delete alias", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("alias with id '%s' is deleted", id)})
+}
+
+// maxDomainLength is the maximum length of a host name and the size of the domain columns.
+const maxDomainLength = 253
+
+func canonicalHostOrRaw(host string) string {
+	if canonical, err := util.CanonicalHost(host); err == nil {
+		return canonical
+	}
+	return host
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultStreamConcurrency = 20
+	defaultStreamLineSize    = 64 * 1024
+)
+
+// StreamAllowedCrawl godoc
+// @Summary Check crawl permissions for a stream of URLs
+// @Description Read newline-delimited JSON records from the request body and write the verdicts back as NDJSON as each one completes. The records are checked concurrently, so the results are unordered and tagged with the 'index' of the record. Invalid records get a result with the INVALID_REQUEST error code
+// @Tags Crawling
+// @Accept application/x-ndjson
+// @Produce application/x-ndjson
+// @Param records body model.CrawlStreamRecord true "NDJSON records, one per line"
+// @Success 200 {object} model.CrawlStreamResult "NDJSON results, one per line"
+// @Router /crawl-allowed/stream [post]
+func (h *RuleApiHandler) StreamAllowedCrawl(c *gin.Context) {
+	// the results are written while the body is still being read
+	if err := http.NewResponseController(c.Writer).EnableFullDuplex(); err != nil {
+		slog.Debug("full duplex is not supported.", slog.String("err", err.Error()))
+	}
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	results := make(chan model.CrawlStreamResult)
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		encoder := json.NewEncoder(c.Writer)
+		for result := range results {
+			if err := encoder.Encode(result); err != nil {
+				slog.Debug("failed to write stream result.", slog.String("err", err.Error()))
+				continue
+			}
+			c.Writer.Flush()
+		}
+	}()
+
+	concurrency, maxLineSize := h.streamLimits()
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	reader := bufio.NewReaderSize(c.Request.Body, maxLineSize)
+	index := 0
+	ctx := c.Request.Context()
+	for ctx.Err() == nil {
+		line, err := reader.ReadSlice('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			// the rest of the body is not read, the last result reports why. The incomplete record is skipped
+			results <- readError(index, err, maxLineSize)
+			break
+		}
+		if line = bytes.TrimSpace(line); len(line) != 0 {
+			i := index
+			index++
+			var record model.CrawlStreamRecord
+			if jsonErr := json.Unmarshal(line, &record); jsonErr != nil {
+				results <- streamError(i, model.ErrorCodeInvalidRequest,
+					fmt.Sprintf("invalid json record. %s", jsonErr.Error()))
+			} else {
+				semaphore <- struct{}{}
+				wg.Add(1)
+				go func() {
+					defer func() {
+						<-semaphore
+						wg.Done()
+					}()
+					resp, _ := h.CheckCrawl(c.Request.Context(), record.Url, record.UserAgent, record.Purpose)
+					results <- model.CrawlStreamResult{
+						Index:                i,
+						Url:                  record.Url,
+						UserAgent:            record.UserAgent,
+						AllowedCrawlResponse: resp,
+					}
+				}()
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	wg.Wait()
+	close(results)
+	<-written
+}
+
+func readError(index int, err error, maxLineSize int) model.CrawlStreamResult {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return streamError(index, model.ErrorCodeBodyTooLarge,
+			fmt.Sprintf("the request body exceeds the limit of %d bytes", maxBytesErr.Limit))
+	case errors.Is(err, bufio.ErrBufferFull):
+		return streamError(index, model.ErrorCodeInvalidRequest,
+			fmt.Sprintf("the record exceeds the limit of %d bytes", maxLineSize))
+	default:
+		return streamError(index, model.ErrorCodeInvalidRequest,
+			fmt.Sprintf("failed to read the request body. %s", err.Error()))
+	}
+}
+
+func streamError(index int, errorCode string, message string) model.CrawlStreamResult {
+	return model.CrawlStreamResult{
+		Index: index,
+		AllowedCrawlResponse: model.AllowedCrawlResponse{
+			StatusCode: http.StatusBadRequest,
+			Error:      message,
+			ErrorCode:  errorCode,
+		},
+	}
+}
+
+func (h *RuleApiHandler) streamLimits() (int, int) {
+	concurrency, maxLineSize := defaultStreamConcurrency, defaultStreamLineSize
+	if h.cfg.CrawlStreamSettings != nil {
+		if h.cfg.CrawlStreamSettings.Concurrency > 0 {
+			concurrency = h.cfg.CrawlStreamSettings.Concurrency
+		}
+		if h.cfg.CrawlStreamSettings.MaxLineSizeKb > 0 {
+			maxLineSize = h.cfg.CrawlStreamSettings.MaxLineSizeKb * 1024
+		}
+	}
+	return concurrency, maxLineSize
+}
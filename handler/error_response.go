@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/internal/persistence"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIdHeader = "X-Request-Id"
+	requestIdKey    = "request_id"
+)
+
+var errorCodes = map[int]string{
+	http.StatusBadRequest:            "BAD_REQUEST",
+	http.StatusUnauthorized:          "UNAUTHORIZED",
+	http.StatusForbidden:             "FORBIDDEN",
+	http.StatusNotFound:              "NOT_FOUND",
+	http.StatusConflict:              "CONFLICT",
+	http.StatusRequestEntityTooLarge: "PAYLOAD_TOO_LARGE",
+	http.StatusUnprocessableEntity:   "UNPROCESSABLE_ENTITY",
+	http.StatusInternalServerError:   "INTERNAL_ERROR",
+}
+
+// RequestId sets the request id from the X-Request-Id header, or generates a new one. The id is returned in the
+// X-Request-Id header and in the error responses.
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(RequestIdHeader)
+		if requestId == "" || len(requestId) > 128 {
+			b := make([]byte, 16)
+			_, _ = rand.Read(b)
+			requestId = hex.EncodeToString(b)
+		}
+		c.Set(requestIdKey, requestId)
+		c.Header(RequestIdHeader, requestId)
+		c.Next()
+	}
+}
+
+// AbortWithError aborts the request with the model.ErrorResponse. The error is returned as the details of the client
+// errors (4xx). The server errors may contain internal details, e.g. the database address, so the error is only logged
+// with the request id and the client gets the message.
+func AbortWithError(c *gin.Context, statusCode int, message string, err error) {
+	resp := model.ErrorResponse{
+		Code:      errorCodes[statusCode],
+		Message:   message,
+		RequestId: c.GetString(requestIdKey),
+	}
+	if resp.Code == "" {
+		resp.Code = errorCodes[http.StatusInternalServerError]
+	}
+	if err != nil {
+		if statusCode < http.StatusInternalServerError {
+			resp.Details = err.Error()
+		} else {
+			slog.Error(message+".", slog.String("request_id", resp.RequestId), slog.String("err", err.Error()))
+		}
+	}
+	c.AbortWithStatusJSON(statusCode, resp)
+}
+
+// storageErrorStatus maps the error of the rule storage to the http status.
+func storageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, persistence.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, persistence.ErrDuplicate):
+		return http.StatusConflict
+	case errors.Is(err, persistence.ErrInvalidDomain):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/IliaW/rule-api/config"
+	"github.com/IliaW/rule-api/internal/jobs"
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/internal/persistence"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMaxUploadSizeMb = 1024
+
+	// the sizes of the user_agent and purpose columns of the crawl_job table
+	maxJobUserAgentLength = 255
+	maxJobPurposeLength   = 50
+)
+
+type JobHandler struct {
+	cfg     *config.Config
+	jobRepo persistence.JobStorage
+}
+
+func NewJobHandler(cfg *config.Config, jobRepo persistence.JobStorage) *JobHandler {
+	return &JobHandler{
+		cfg:     cfg,
+		jobRepo: jobRepo,
+	}
+}
+
+// CreateJob godoc
+// @Summary Create a crawl check job
+// @Description Upload a list of URLs, one per line, to be checked in the background. The list is sent as the request body or as the 'file' field of a multipart form. Use /jobs/{id} to follow the progress and /jobs/{id}/result to download the results
+// @Tags Jobs
+// @Accept plain
+// @Accept mpfd
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_agent query string true "User-Agent"
+// @Param purpose query string false "Crawl purpose, e.g. 'search-indexing' or 'ai-training'"
+// @Param file formData file false "URL list, if sent as a multipart form"
+// @Success 202 {object} model.Job
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 413 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /jobs [post]
+func (h *JobHandler) CreateJob(c *gin.Context) {
+	userAgent := c.Query("user_agent")
+	if userAgent == "" {
+		AbortWithError(c, http.StatusBadRequest, "'user_agent' query parameter is required", nil)
+		return
+	}
+	if utf8.RuneCountInString(userAgent) > maxJobUserAgentLength {
+		AbortWithError(c, http.StatusBadRequest,
+			fmt.Sprintf("'user_agent' must not be longer than %d characters", maxJobUserAgentLength), nil)
+		return
+	}
+	purpose := c.Query("purpose")
+	if utf8.RuneCountInString(purpose) > maxJobPurposeLength {
+		AbortWithError(c, http.StatusBadRequest,
+			fmt.Sprintf("'purpose' must not be longer than %d characters", maxJobPurposeLength), nil)
+		return
+	}
+	if _, ok := h.cfg.CrawlPurposes[purpose]; purpose != "" && !ok {
+		AbortWithError(c, http.StatusBadRequest, fmt.Sprintf("unsupported 'purpose' query parameter '%s'", purpose),
+			nil)
+		return
+	}
+
+	maxUploadSizeMb := int64(defaultMaxUploadSizeMb)
+	if h.cfg.JobSettings.MaxUploadSizeMb > 0 {
+		maxUploadSizeMb = h.cfg.JobSettings.MaxUploadSizeMb
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadSizeMb*1024*1024)
+	body, err := uploadedList(c)
+	if err != nil {
+		AbortWithError(c, uploadErrorStatus(err), "failed to read the url list", err)
+		return
+	}
+	defer func() {
+		if err := body.Close(); err != nil {
+			slog.Error("failed to close the url list.", slog.String("err", err.Error()))
+		}
+	}()
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 4096), jobs.MaxLineSize)
+	first, err := nextUrl(scanner)
+	if err != nil {
+		AbortWithError(c, uploadErrorStatus(err), "failed to read the url list", err)
+		return
+	}
+	if first == "" {
+		AbortWithError(c, http.StatusBadRequest, "the url list is empty", nil)
+		return
+	}
+
+	job := &model.Job{
+		ID:        uuid.NewString(),
+		UserAgent: userAgent,
+		Purpose:   purpose,
+	}
+	if err = h.jobRepo.CreateJob(job, urlList(first, scanner)); err != nil {
+		if status := uploadErrorStatus(err); status != http.StatusInternalServerError {
+			AbortWithError(c, status, "failed to save the url list", err)
+			return
+		}
+		slog.Error("failed to save job.", slog.String("err", err.Error()))
+		AbortWithError(c, storageErrorStatus(err), "failed to save job", err)
+		return
+	}
+	slog.Info("job created.", slog.String("id", job.ID), slog.Int("total", job.Total))
+
+	c.Header("Location", fmt.Sprintf("%s/jobs/%s", h.cfg.RuleApiUrlPath, job.ID))
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetJob godoc
+// @Summary Get a crawl check job
+// @Description Get the status and the progress of the job
+// @Tags Jobs
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} model.Job
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /jobs/{id} [get]
+func (h *JobHandler) GetJob(c *gin.Context) {
+	job, err := h.jobRepo.GetJob(c.Param("id"))
+	if err != nil {
+		AbortWithError(c, storageErrorStatus(err), "failed to get job", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// GetJobResult godoc
+// @Summary Download the result of a crawl check job
+// @Description Download the verdicts of the completed job as NDJSON, one line per URL in the order of the uploaded list. 'index' is the zero-based number of the URL in the list
+// @Tags Jobs
+// @Produce application/x-ndjson
+// @Security ApiKeyAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} model.CrawlStreamResult "NDJSON results, one per line"
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /jobs/{id}/result [get]
+func (h *JobHandler) GetJobResult(c *gin.Context) {
+	job, err := h.jobRepo.GetJob(c.Param("id"))
+	if err != nil {
+		AbortWithError(c, storageErrorStatus(err), "failed to get job", err)
+		return
+	}
+	if job.Status != model.JobStatusCompleted {
+		AbortWithError(c, http.StatusConflict, fmt.Sprintf("the job is %s", job.Status), nil)
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.ndjson\"", job.ID))
+	c.Status(http.StatusOK)
+	if err = h.jobRepo.WriteJobResult(job.ID, c.Writer); err != nil {
+		// the status is already sent, the client gets an incomplete result
+		slog.Error("failed to write job result.", slog.String("id", job.ID), slog.String("err", err.Error()))
+		c.Abort()
+	}
+}
+
+// uploadedList returns the 'file' field of the multipart form or the request body.
+func uploadedList(c *gin.Context) (io.ReadCloser, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		return c.Request.Body, nil
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	var file multipart.File
+	if file, err = fileHeader.Open(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// nextUrl returns the next non-empty line of the list. Returns an empty string at the end of the list.
+func nextUrl(scanner *bufio.Scanner) (string, error) {
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			return line, nil
+		}
+	}
+	return "", scanner.Err()
+}
+
+// urlList returns the urls of the list starting from the first one, which is already read from the scanner.
+func urlList(first string, scanner *bufio.Scanner) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		var err error
+		for url := first; url != ""; {
+			if !yield(url, nil) {
+				return
+			}
+			if url, err = nextUrl(scanner); err != nil {
+				yield("", err)
+				return
+			}
+		}
+	}
+}
+
+func uploadErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, bufio.ErrTooLong), errors.Is(err, http.ErrMissingFile),
+		errors.Is(err, multipart.ErrMessageTooLarge):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
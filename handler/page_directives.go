@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/IliaW/rule-api/engine"
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/util"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/html"
+)
+
+const (
+	// maxPageBodySize limits the part of the page that is read to find the meta tags
+	maxPageBodySize = 1 << 20
+
+	directiveSourceHeader = "x-robots-tag"
+	directiveSourceMeta   = "meta"
+)
+
+// directives that carry a value after a colon and must not be confused with a user agent prefix
+var directivesWithValue = []string{"unavailable_after", "max-snippet", "max-image-preview", "max-video-preview"}
+
+// GetPageDirectives godoc
+// @Summary Get page-level robots directives
+// @Description Fetch the page and return the directives from the X-Robots-Tag headers and the robots meta tags that apply to the user agent
+// @Tags Crawling
+// @Produce json
+// @Param url query string true "Page URL"
+// @Param user_agent query string true "User agent to check"
+// @Param headers_only query bool false "Request only the headers (HEAD request). Meta tags are not checked"
+// @Success 200 {object} model.PageDirectivesResponse "Response object"
+// @Router /page-directives [get]
+func (h *RuleApiHandler) GetPageDirectives(c *gin.Context) {
+	url := c.Query("url")
+	if url == "" {
+		c.JSON(http.StatusBadRequest, model.PageDirectivesResponse{
+			Directives: []string{},
+			StatusCode: http.StatusBadRequest,
+			Error:      "'url' query parameter is required",
+		})
+		return
+	}
+	userAgent := c.Query("user_agent")
+	if userAgent == "" {
+		c.JSON(http.StatusBadRequest, model.PageDirectivesResponse{
+			Url:        url,
+			Directives: []string{},
+			StatusCode: http.StatusBadRequest,
+			Error:      "'user_agent' query parameter is required",
+		})
+		return
+	}
+	headersOnly, err := strconv.ParseBool(c.DefaultQuery("headers_only", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.PageDirectivesResponse{
+			Url:        url,
+			UserAgent:  userAgent,
+			Directives: []string{},
+			StatusCode: http.StatusBadRequest,
+			Error:      "unable to parse 'headers_only' query parameter",
+		})
+		return
+	}
+
+	if canonicalUrl, err := util.CanonicalizeUrl(url); err == nil {
+		url = canonicalUrl
+	}
+
+	page, err := h.getPageDirectives(c.Request.Context(), url, headersOnly)
+	if err != nil {
+		errorCode, message := engine.ClassifyFetchError(err, "page")
+		slog.Warn("failed to get page directives.", slog.String("url", url), slog.String("error_code", errorCode),
+			slog.String("err", err.Error()))
+		statusCode := engine.FetchErrorStatus(err)
+		c.JSON(statusCode, model.PageDirectivesResponse{
+			Url:        url,
+			UserAgent:  userAgent,
+			Directives: []string{},
+			StatusCode: statusCode,
+			Error:      message,
+			ErrorCode:  errorCode,
+		})
+		return
+	}
+
+	resp := model.PageDirectivesResponse{
+		Url:        url,
+		UserAgent:  userAgent,
+		Directives: []string{},
+		StatusCode: page.StatusCode,
+	}
+	productToken := userAgentProductToken(userAgent)
+	for _, group := range page.Groups {
+		if group.UserAgent != "" && group.UserAgent != productToken {
+			continue
+		}
+		for _, directive := range group.Directives {
+			if !slices.Contains(resp.Directives, directive) {
+				resp.Directives = append(resp.Directives, directive)
+			}
+		}
+	}
+	for _, directive := range resp.Directives {
+		switch directive {
+		case "noindex":
+			resp.NoIndex = true
+		case "nofollow":
+			resp.NoFollow = true
+		case "none":
+			resp.NoIndex = true
+			resp.NoFollow = true
+		case "noai":
+			resp.NoAi = true
+		case "noimageai":
+			resp.NoImageAi = true
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *RuleApiHandler) getPageDirectives(ctx context.Context, url string,
+	headersOnly bool) (*model.PageDirectives, error) {
+	cacheKey := url
+	if headersOnly {
+		cacheKey = "HEAD " + url
+	}
+	if file, ok := h.cache.GetPageDirectives(cacheKey); ok {
+		var page model.PageDirectives
+		if err := json.Unmarshal(file, &page); err == nil {
+			return &page, nil
+		}
+	}
+
+	method := http.MethodGet
+	if headersOnly {
+		method = http.MethodHead
+	}
+	tResp, err := h.fetcher.Fetch(ctx, method, url, maxPageBodySize)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.PageDirectives{
+		StatusCode: tResp.StatusCode,
+		Groups:     parseRobotsTags(tResp.Header.Values("X-Robots-Tag")),
+	}
+	if !headersOnly && strings.Contains(strings.ToLower(tResp.Header.Get("Content-Type")), "html") {
+		page.Groups = append(page.Groups, parseRobotsMeta(tResp.Body)...)
+	}
+
+	if isSuccess(tResp.StatusCode) {
+		file, err := json.Marshal(page)
+		if err != nil {
+			slog.Error("failed to encode page directives.", slog.String("err", err.Error()))
+		} else {
+			h.cache.SavePageDirectives(cacheKey, file)
+		}
+	}
+
+	return page, nil
+}
+
+// parseRobotsTags parses X-Robots-Tag header values. A value may start with a user agent,
+// e.g. 'googlebot: noindex, nofollow'.
+func parseRobotsTags(values []string) []model.DirectiveGroup {
+	groups := make([]model.DirectiveGroup, 0, len(values))
+	for _, value := range values {
+		userAgent := ""
+		if prefix, rest, found := strings.Cut(value, ":"); found {
+			prefix = strings.ToLower(strings.TrimSpace(prefix))
+			if !strings.ContainsAny(prefix, " ,") && !slices.Contains(directivesWithValue, prefix) {
+				userAgent = prefix
+				value = rest
+			}
+		}
+		groups = append(groups, model.DirectiveGroup{
+			UserAgent:  userAgent,
+			Source:     directiveSourceHeader,
+			Directives: splitDirectives(value),
+		})
+	}
+
+	return groups
+}
+
+// parseRobotsMeta finds the <meta name="robots"> and <meta name="{product token}"> tags in the page head. The meta
+// names that are not product tokens, e.g. <meta name="og:title">, are skipped.
+func parseRobotsMeta(body []byte) []model.DirectiveGroup {
+	var groups []model.DirectiveGroup
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return groups
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "head" {
+				return groups
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			if string(name) == "body" {
+				return groups
+			}
+			if string(name) != "meta" || !hasAttr {
+				continue
+			}
+			var metaName, content string
+			for more := true; more; {
+				var key, val []byte
+				key, val, more = tokenizer.TagAttr()
+				switch string(key) {
+				case "name":
+					metaName = strings.ToLower(strings.TrimSpace(string(val)))
+				case "content":
+					content = string(val)
+				}
+			}
+			if metaName == "" || content == "" {
+				continue
+			}
+			// the other meta names may be crawler names, e.g. <meta name="googlebot-news">. They apply only to
+			// the user agent with the same product token
+			userAgent := ""
+			if metaName != "robots" {
+				if !isProductToken(metaName) {
+					continue
+				}
+				userAgent = metaName
+			}
+			groups = append(groups, model.DirectiveGroup{
+				UserAgent:  userAgent,
+				Source:     directiveSourceMeta,
+				Directives: splitDirectives(content),
+			})
+		}
+	}
+}
+
+func splitDirectives(value string) []string {
+	directives := make([]string, 0)
+	for _, d := range strings.Split(value, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d != "" {
+			directives = append(directives, d)
+		}
+	}
+
+	return directives
+}
+
+// isProductToken reports whether the name is a user agent product token: letters, digits, '-' and '_'.
+func isProductToken(name string) bool {
+	return name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return !isTokenRune(r)
+	}) == -1
+}
+
+func isTokenRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_'
+}
+
+// userAgentProductToken returns the lowercase product token of the user agent. It is the crawler name in the
+// 'compatible' comment, e.g. 'googlebot' for 'Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)'
+// or the name of the first product, e.g. 'gptbot' for 'GPTBot/1.0'. The url and the other words of the comment are
+// not product tokens. Empty if the user agent has no product token.
+func userAgentProductToken(userAgent string) string {
+	userAgent = strings.ToLower(userAgent)
+	for rest := userAgent; ; {
+		_, comment, found := strings.Cut(rest, "(")
+		if !found {
+			break
+		}
+		comment, rest, _ = strings.Cut(comment, ")")
+		parts := strings.Split(comment, ";")
+		for i, part := range parts {
+			if strings.TrimSpace(part) != "compatible" {
+				continue
+			}
+			for _, product := range parts[i+1:] {
+				name, _, isProduct := strings.Cut(strings.TrimSpace(product), "/")
+				if isProduct && isProductToken(name) {
+					return name
+				}
+			}
+		}
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(userAgent), " ")
+	name, _, _ := strings.Cut(first, "/")
+	if !isProductToken(name) {
+		return ""
+	}
+
+	return name
+}
@@ -1,14 +1,19 @@
 package handler
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/IliaW/rule-api/config"
+	"github.com/IliaW/rule-api/engine"
 	cacheClient "github.com/IliaW/rule-api/internal/cache"
 	"github.com/IliaW/rule-api/internal/model"
 	"github.com/IliaW/rule-api/internal/persistence"
@@ -19,22 +24,38 @@ import (
 )
 
 type RuleApiHandler struct {
-	cfg        *config.Config
-	cache      cacheClient.CachedClient
-	ruleRepo   persistence.RuleStorage
-	httpClient *http.Client
-	metrics    *telemetry.ApiMetrics
+	cfg       *config.Config
+	cache     cacheClient.CachedClient
+	ruleRepo  persistence.RuleStorage
+	fetcher   engine.Fetcher
+	evaluator *engine.Evaluator
+	metrics   *telemetry.ApiMetrics
+	now       func() time.Time
 }
 
 func NewRuleApiHandler(cfg *config.Config, cache cacheClient.CachedClient, ruleRepo persistence.RuleStorage,
 	httpClient *http.Client, metrics *telemetry.ApiMetrics) *RuleApiHandler {
-	return &RuleApiHandler{
-		cfg:        cfg,
-		cache:      cache,
-		ruleRepo:   ruleRepo,
-		httpClient: httpClient,
-		metrics:    metrics,
+	h := &RuleApiHandler{
+		cfg:      cfg,
+		cache:    cache,
+		ruleRepo: ruleRepo,
+		metrics:  metrics,
+		now:      time.Now,
 	}
+	var userAgent string
+	var maxResponseSize int64
+	if cfg != nil {
+		userAgent = cfg.RuleUserAgent
+		if cfg.HttpClientSettings != nil {
+			maxResponseSize = cfg.HttpClientSettings.MaxResponseSizeKb * 1024
+		}
+	}
+	h.fetcher = engine.NewHttpFetcher(httpClient, userAgent, maxResponseSize)
+	opts := engine.OptionsFromConfig(cfg)
+	// the clock of the handler is read on every check, so it can be replaced in tests
+	opts.Now = func() time.Time { return h.now() }
+	h.evaluator = engine.New(opts, ruleRepo, cache, h.fetcher)
+	return h
 }
 
 // GetAllowedCrawl godoc
@@ -42,90 +63,42 @@ func NewRuleApiHandler(cfg *config.Config, cache cacheClient.CachedClient, ruleR
 // @Description Check if the given user agent is allowed to crawl the specified URL based on the robots.txt rules
 // @Tags Crawling
 // @Produce json
-// @Param url query string true "URL to check"
+// @Param url query string true "URL to check. Without a scheme, https is tried first and http is the fallback"
 // @Param user_agent query string true "User agent to check"
+// @Param purpose query string false "Crawl purpose from the 'crawl_purposes' config. The mapped product tokens are evaluated along with the user agent. 'ai-training' adds the ai.txt verdict"
 // @Success 200 {object} model.AllowedCrawlResponse "Response object"
 // @Router /crawl-allowed [get]
 func (h *RuleApiHandler) GetAllowedCrawl(c *gin.Context) {
-	url := c.Query("url")
-	if url == "" {
-		c.JSON(http.StatusBadRequest, model.AllowedCrawlResponse{
-			IsAllowed:  false,
-			Blocked:    false,
-			StatusCode: http.StatusBadRequest,
-			Error:      "'url' query parameter is required",
-		})
-		h.metrics.ErrorResponseCounter(1)
-		return
-	}
-	userAgent := c.Query("user_agent")
-	if userAgent == "" {
-		c.JSON(http.StatusBadRequest, model.AllowedCrawlResponse{
-			IsAllowed:  false,
-			Blocked:    false,
-			StatusCode: http.StatusBadRequest,
-			Error:      "'user_agent' query parameter is required",
-		})
+	resp, statusCode := h.CheckCrawl(c.Request.Context(), c.Query("url"), c.Query("user_agent"), c.Query("purpose"))
+	c.JSON(statusCode, resp)
+}
+
+// CheckCrawl checks if the user agent is allowed to crawl the url with the evaluator and counts the response.
+// Returns the verdict and the http status of the response. It is shared by the http and grpc APIs.
+func (h *RuleApiHandler) CheckCrawl(ctx context.Context, url string, userAgent string,
+	purpose string) (model.AllowedCrawlResponse, int) {
+	resp, err := h.evaluator.CheckPurpose(ctx, url, userAgent, purpose)
+	if err != nil {
 		h.metrics.ErrorResponseCounter(1)
-		return
+		return resp, resp.StatusCode
 	}
+	h.metrics.SuccessResponseCounter(1)
+	return resp, http.StatusOK
+}
 
-	var robotsTxt string
-	var targetResponseStatusCode int
-	blocked := false
-
-	// check the custom rule for the given url in database
-	rule, err := h.ruleRepo.GetByUrl(url)
-	if err == nil && rule != nil && rule.RobotsTxt != "" {
-		robotsTxt = rule.RobotsTxt
-		blocked = rule.Blocked
-		targetResponseStatusCode = http.StatusOK
-	} else {
-		// upload the robots.txt file if custom rule is not found in database
-		tResp, err := h.getRobotsTxt(url)
+// CheckCrawlGroup checks the urls of the same origin. The robots.txt file is resolved once for the whole group.
+// The verdicts are in the order of the urls.
+func (h *RuleApiHandler) CheckCrawlGroup(ctx context.Context, urls []string, userAgent string,
+	purpose string) []model.AllowedCrawlResponse {
+	results, errs := h.evaluator.CheckGroup(ctx, urls, userAgent, purpose)
+	for _, err := range errs {
 		if err != nil {
-			// most likely, there is no access to the URL, or the robots.txt file does not exist
-			c.JSON(http.StatusInternalServerError, model.AllowedCrawlResponse{
-				IsAllowed:  false,
-				Blocked:    blocked,
-				StatusCode: http.StatusInternalServerError,
-				Error:      err.Error(),
-			})
 			h.metrics.ErrorResponseCounter(1)
-			return
-		}
-		if !isSuccess(tResp.StatusCode) {
-			c.JSON(http.StatusOK, model.AllowedCrawlResponse{
-				IsAllowed:  false,
-				Blocked:    blocked,
-				StatusCode: tResp.StatusCode,
-				Error:      string(tResp.Body),
-			})
+		} else {
 			h.metrics.SuccessResponseCounter(1)
-			return
 		}
-		robotsTxt = string(tResp.Body)
-		targetResponseStatusCode = tResp.StatusCode
-	}
-
-	if ok := grobotstxt.AgentAllowed(robotsTxt, userAgent, url); ok {
-		c.JSON(http.StatusOK, model.AllowedCrawlResponse{
-			IsAllowed:  true,
-			Blocked:    blocked,
-			StatusCode: targetResponseStatusCode,
-			Error:      "",
-		})
-		h.metrics.SuccessResponseCounter(1)
-		return
 	}
-
-	c.JSON(http.StatusOK, model.AllowedCrawlResponse{
-		IsAllowed:  false,
-		Blocked:    blocked,
-		StatusCode: targetResponseStatusCode,
-		Error:      "",
-	})
-	h.metrics.SuccessResponseCounter(1)
+	return results
 }
 
 // GetCustomRule godoc
@@ -136,21 +109,30 @@ func (h *RuleApiHandler) GetAllowedCrawl(c *gin.Context) {
 // @Param id query string false "Custom rule ID"
 // @Param url query string false "Custom rule URL"
 // @Success 200 {object} model.Rule "Custom rule object"
+// @Failure 400 {object} model.ErrorResponse "Missing or invalid query parameter"
+// @Failure 404 {object} model.ErrorResponse "Custom rule not found"
+// @Failure 422 {object} model.ErrorResponse "Invalid URL"
+// @Failure 500 {object} model.ErrorResponse "Database error"
+// @Failure 401 {object} model.ErrorResponse "Missing or invalid API key"
+// @Failure 403 {object} model.ErrorResponse "API key is not active"
 // @Security ApiKeyAuth
 // @Router /custom-rule [get]
 func (h *RuleApiHandler) GetCustomRule(c *gin.Context) {
 	id := c.Query("id")
 	url := c.Query("url")
 	if id == "" && url == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "'id' or 'url' query parameter is required"})
+		AbortWithError(c, http.StatusBadRequest, "'id' or 'url' query parameter is required", nil)
 		return
 	}
 
 	if id != "" {
+		if !isValidId(id) {
+			AbortWithError(c, http.StatusBadRequest, "'id' query parameter must be an integer", nil)
+			return
+		}
 		rule, err := h.ruleRepo.GetById(id)
 		if err != nil {
-			c.JSON(http.StatusNotFound,
-				gin.H{"error": fmt.Sprintf("failed to get rule by id. %s", err.Error())})
+			AbortWithError(c, storageErrorStatus(err), "failed to get rule by id", err)
 			return
 		}
 		c.JSON(http.StatusOK, rule)
@@ -159,14 +141,119 @@ func (h *RuleApiHandler) GetCustomRule(c *gin.Context) {
 
 	rule, err := h.ruleRepo.GetByUrl(url)
 	if err != nil {
-		c.JSON(http.StatusNotFound,
-			gin.H{"error": fmt.Sprintf("failed to get rule by url. %s", err.Error())})
+		AbortWithError(c, storageErrorStatus(err), "failed to get rule by url", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, rule)
 }
 
+// GetCustomRules godoc
+// @Summary List custom rules
+// @Description List the custom rules page by page. The rules can be filtered by the domain and robots.txt substrings, the blocked flag and the created and updated date ranges
+// @Tags Custom Rule
+// @Produce json
+// @Param domain query string false "Case-insensitive substring of the domain"
+// @Param robots_txt query string false "Case-insensitive substring of the robots.txt content"
+// @Param blocked query bool false "Only the blocked or only the not blocked rules"
+// @Param created_from query string false "Created at or after the time, RFC 3339"
+// @Param created_to query string false "Created at or before the time, RFC 3339"
+// @Param updated_from query string false "Updated at or after the time, RFC 3339"
+// @Param updated_to query string false "Updated at or before the time, RFC 3339"
+// @Param sort query string false "Sort key: updated_at (default) or domain"
+// @Param order query string false "Sort order: asc or desc. The default is desc for updated_at and asc for domain"
+// @Param limit query int false "Page size from 1 to 500. The default is 50"
+// @Param cursor query string false "'next_cursor' of the previous page"
+// @Success 200 {object} model.RulePage "Page of custom rules"
+// @Failure 400 {object} model.ErrorResponse "Invalid query parameter"
+// @Failure 500 {object} model.ErrorResponse "Database error"
+// @Failure 401 {object} model.ErrorResponse "Missing or invalid API key"
+// @Failure 403 {object} model.ErrorResponse "API key is not active"
+// @Security ApiKeyAuth
+// @Router /custom-rules [get]
+func (h *RuleApiHandler) GetCustomRules(c *gin.Context) {
+	filter := &model.RuleFilter{
+		Domain:    c.Query("domain"),
+		RobotsTxt: c.Query("robots_txt"),
+		SortBy:    c.DefaultQuery("sort", model.RuleSortUpdatedAt),
+		Order:     c.Query("order"),
+	}
+	if filter.SortBy != model.RuleSortUpdatedAt && filter.SortBy != model.RuleSortDomain {
+		AbortWithError(c, http.StatusBadRequest, "'sort' query parameter must be 'updated_at' or 'domain'", nil)
+		return
+	}
+	switch {
+	case filter.Order == "" && filter.SortBy == model.RuleSortDomain:
+		filter.Order = model.SortAsc
+	case filter.Order == "":
+		filter.Order = model.SortDesc
+	case filter.Order != model.SortAsc && filter.Order != model.SortDesc:
+		AbortWithError(c, http.StatusBadRequest, "'order' query parameter must be 'asc' or 'desc'", nil)
+		return
+	}
+
+	if b := c.Query("blocked"); b != "" {
+		blocked, err := strconv.ParseBool(b)
+		if err != nil {
+			AbortWithError(c, http.StatusBadRequest, "unable to parse 'blocked' query parameter", err)
+			return
+		}
+		filter.Blocked = &blocked
+	}
+	for _, param := range []struct {
+		name  string
+		field **time.Time
+	}{
+		{name: "created_from", field: &filter.CreatedFrom},
+		{name: "created_to", field: &filter.CreatedTo},
+		{name: "updated_from", field: &filter.UpdatedFrom},
+		{name: "updated_to", field: &filter.UpdatedTo},
+	} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		// the columns are TIMESTAMP without a time zone and hold UTC times. The offset is ignored by postgres, so the
+		// time is converted to UTC first
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			AbortWithError(c, http.StatusBadRequest,
+				fmt.Sprintf("'%s' query parameter must be a RFC 3339 time", param.name), nil)
+			return
+		}
+		t = t.UTC()
+		*param.field = &t
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultRulesLimit)))
+	if err != nil || limit < 1 || limit > maxRulesLimit {
+		AbortWithError(c, http.StatusBadRequest,
+			fmt.Sprintf("'limit' query parameter must be an integer from 1 to %d", maxRulesLimit), nil)
+		return
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		if filter.After, err = decodeRuleCursor(cursor, filter); err != nil {
+			AbortWithError(c, http.StatusBadRequest, "invalid 'cursor' query parameter", err)
+			return
+		}
+	}
+	// one more rule is requested to know if there is the next page
+	filter.Limit = limit + 1
+
+	rules, err := h.ruleRepo.List(filter)
+	if err != nil {
+		AbortWithError(c, storageErrorStatus(err), "failed to list rules", err)
+		return
+	}
+	page := model.RulePage{Rules: rules}
+	if len(rules) > limit {
+		page.Rules = rules[:limit]
+		page.NextCursor = encodeRuleCursor(filter, rules[limit-1])
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
 // CreateCustomRule godoc
 // @Summary Create a custom rule
 // @Description Create a new custom rule by providing a URL and the corresponding rule file
@@ -177,34 +264,45 @@ func (h *RuleApiHandler) GetCustomRule(c *gin.Context) {
 // @Param blocked query bool false "Block the domain from being crawled"
 // @Param file body string true "Custom rule file content"
 // @Success 200 {object} string "Custom rule created successfully"
+// @Failure 400 {object} model.ErrorResponse "Missing or invalid query parameter or empty body"
+// @Failure 409 {object} model.ErrorResponse "Custom rule for the domain already exists"
+// @Failure 422 {object} model.ErrorResponse "Invalid URL or the domain is too long"
+// @Failure 500 {object} model.ErrorResponse "Database error"
+// @Failure 401 {object} model.ErrorResponse "Missing or invalid API key"
+// @Failure 403 {object} model.ErrorResponse "API key is not active"
 // @Security ApiKeyAuth
 // @Router /custom-rule [post]
 func (h *RuleApiHandler) CreateCustomRule(c *gin.Context) {
 	url := c.Query("url")
 	if url == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "'url' query parameter is required"})
+		AbortWithError(c, http.StatusBadRequest, "'url' query parameter is required", nil)
 		return
 	}
 
-	b := c.DefaultQuery("blocked", "false")
-	blocked, err := strconv.ParseBool(b)
+	blocked, err := strconv.ParseBool(c.DefaultQuery("blocked", "false"))
 	if err != nil {
-		blocked = false
+		AbortWithError(c, http.StatusBadRequest, "unable to parse 'blocked' query parameter", err)
+		return
 	}
 
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("unable to read file. %s", err.Error())})
+		AbortWithError(c, http.StatusBadRequest, "unable to read file", err)
 		return
 	}
 	if len(body) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "custom rules are not found or empty"})
+		AbortWithError(c, http.StatusBadRequest, "custom rules are not found or empty", nil)
 		return
 	}
 
 	domain, err := util.GetDomain(url)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to parse url. %s", err.Error())})
+		AbortWithError(c, http.StatusUnprocessableEntity, "failed to parse url", err)
+		return
+	}
+	if len(domain) > maxDomainLength {
+		AbortWithError(c, http.StatusUnprocessableEntity,
+			fmt.Sprintf("the domain must not be longer than %d characters", maxDomainLength), nil)
 		return
 	}
 
@@ -214,8 +312,7 @@ func (h *RuleApiHandler) CreateCustomRule(c *gin.Context) {
 		Blocked:   blocked,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError,
-			gin.H{"error": fmt.Sprintf("failed to save custom rule. %v", err.Error())})
+		AbortWithError(c, storageErrorStatus(err), "failed to save custom rule", err)
 		return
 	}
 
@@ -233,34 +330,45 @@ func (h *RuleApiHandler) CreateCustomRule(c *gin.Context) {
 // @Param blocked query bool true "Block the domain from being crawled"
 // @Param file body string true "Updated custom rule file content"
 // @Success 200 {object} model.Rule "Updated custom rule"
+// @Failure 400 {object} model.ErrorResponse "Missing or invalid query parameter or empty body"
+// @Failure 404 {object} model.ErrorResponse "Custom rule not found"
+// @Failure 409 {object} model.ErrorResponse "Custom rule for the domain already exists"
+// @Failure 422 {object} model.ErrorResponse "Invalid URL"
+// @Failure 500 {object} model.ErrorResponse "Database error"
+// @Failure 401 {object} model.ErrorResponse "Missing or invalid API key"
+// @Failure 403 {object} model.ErrorResponse "API key is not active"
 // @Security ApiKeyAuth
 // @Router /custom-rule [put]
 func (h *RuleApiHandler) UpdateCustomRule(c *gin.Context) {
 	id := c.Query("id")
 	url := c.Query("url")
 	if id == "" && url == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "'id' or 'url' query parameter is required"})
+		AbortWithError(c, http.StatusBadRequest, "'id' or 'url' query parameter is required", nil)
+		return
+	}
+	if id != "" && !isValidId(id) {
+		AbortWithError(c, http.StatusBadRequest, "'id' query parameter must be an integer", nil)
 		return
 	}
 
 	b := c.Query("blocked")
 	if b == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "'blocked' query parameter is required"})
+		AbortWithError(c, http.StatusBadRequest, "'blocked' query parameter is required", nil)
 		return
 	}
 	blocked, parseErr := strconv.ParseBool(b)
 	if parseErr != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to parse 'blocked' query parameter"})
+		AbortWithError(c, http.StatusBadRequest, "unable to parse 'blocked' query parameter", parseErr)
 		return
 	}
 
 	body, readErr := io.ReadAll(c.Request.Body)
 	if readErr != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "unable to read file"})
+		AbortWithError(c, http.StatusBadRequest, "unable to read file", readErr)
 		return
 	}
 	if len(body) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "custom rules are not found or empty"})
+		AbortWithError(c, http.StatusBadRequest, "custom rules are not found or empty", nil)
 		return
 	}
 
@@ -269,13 +377,13 @@ func (h *RuleApiHandler) UpdateCustomRule(c *gin.Context) {
 	if id != "" {
 		rule, err = h.ruleRepo.GetById(id)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("failed to get rule by id. %s", err.Error())})
+			AbortWithError(c, storageErrorStatus(err), "failed to get rule by id", err)
 			return
 		}
 	} else {
 		rule, err = h.ruleRepo.GetByUrl(url)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("failed to get rule by url. %s", err.Error())})
+			AbortWithError(c, storageErrorStatus(err), "failed to get rule by url", err)
 			return
 		}
 	}
@@ -291,8 +399,7 @@ func (h *RuleApiHandler) UpdateCustomRule(c *gin.Context) {
 
 	result, err := h.ruleRepo.Update(rule)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError,
-			gin.H{"error": fmt.Sprintf("failed to update custom rule. %v", err.Error())})
+		AbortWithError(c, storageErrorStatus(err), "failed to update custom rule", err)
 		return
 	}
 
@@ -306,80 +413,176 @@ func (h *RuleApiHandler) UpdateCustomRule(c *gin.Context) {
 // @Produce json
 // @Param id query string true "Custom rule ID"
 // @Success 200 {object} string "Rule deleted successfully"
+// @Failure 400 {object} model.ErrorResponse "Missing or invalid query parameter"
+// @Failure 404 {object} model.ErrorResponse "Custom rule not found"
+// @Failure 500 {object} model.ErrorResponse "Database error"
+// @Failure 401 {object} model.ErrorResponse "Missing or invalid API key"
+// @Failure 403 {object} model.ErrorResponse "API key is not active"
 // @Security ApiKeyAuth
 // @Router /custom-rule [delete]
 func (h *RuleApiHandler) DeleteCustomRule(c *gin.Context) {
 	id := c.Query("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "'id' query parameter is required"})
+		AbortWithError(c, http.StatusBadRequest, "'id' query parameter is required", nil)
+		return
+	}
+	if !isValidId(id) {
+		AbortWithError(c, http.StatusBadRequest, "'id' query parameter must be an integer", nil)
 		return
 	}
 
 	err := h.ruleRepo.Delete(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError,
-			gin.H{"error": fmt.Sprintf("failed to delete custom rule. %v", err.Error())})
+		AbortWithError(c, storageErrorStatus(err), "failed to delete custom rule", err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("rule with id '%s' is deleted", id)})
 }
 
-func (h *RuleApiHandler) getRobotsTxt(url string) (*model.TargetResponse, error) {
-	// check if the robots.txt file is already saved in cache
-	file, ok := h.cache.GetRobotsFile(url)
-	if ok {
-		return &model.TargetResponse{
-			StatusCode: http.StatusOK,
-			Body:       file,
-		}, nil
-	}
-	// make get request to fetch the robots.txt file if it is not saved in cache
-	tResp, err := h.requestToRobotsTxt(url)
-	if err != nil {
-		return nil, err
+// CompareCustomRule godoc
+// @Summary Compare a custom rule with the live robots.txt
+// @Description Evaluate sample URLs and user agents against the custom rule and the live robots.txt of its domain
+// @Tags Custom Rule
+// @Accept json
+// @Produce json
+// @Param id query string true "Custom rule ID"
+// @Param samples body model.RuleComparisonRequest true "Sample URLs and user agents"
+// @Success 200 {object} model.RuleComparisonResponse "Comparison result"
+// @Failure 400 {object} model.ErrorResponse "Missing or invalid query parameter or request body"
+// @Failure 404 {object} model.ErrorResponse "Custom rule not found"
+// @Failure 500 {object} model.ErrorResponse "Database error or failed to fetch the live robots.txt"
+// @Failure 401 {object} model.ErrorResponse "Missing or invalid API key"
+// @Failure 403 {object} model.ErrorResponse "API key is not active"
+// @Security ApiKeyAuth
+// @Router /custom-rule/compare [post]
+func (h *RuleApiHandler) CompareCustomRule(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		AbortWithError(c, http.StatusBadRequest, "'id' query parameter is required", nil)
+		return
 	}
-
-	// save the robots.txt file to cache if the request is successful and the body is not empty
-	if isSuccess(tResp.StatusCode) && len(tResp.Body) != 0 {
-		h.cache.SaveRobotsFile(url, tResp.Body)
+	if !isValidId(id) {
+		AbortWithError(c, http.StatusBadRequest, "'id' query parameter must be an integer", nil)
+		return
 	}
 
-	return tResp, nil
-}
+	var samples model.RuleComparisonRequest
+	if err := c.ShouldBindJSON(&samples); err != nil {
+		AbortWithError(c, http.StatusBadRequest, "unable to parse request body", err)
+		return
+	}
+	if len(samples.Urls) == 0 || len(samples.UserAgents) == 0 {
+		AbortWithError(c, http.StatusBadRequest, "'urls' and 'user_agents' must not be empty", nil)
+		return
+	}
 
-func (h *RuleApiHandler) requestToRobotsTxt(url string) (*model.TargetResponse, error) {
-	baseUrl, err := util.GetBaseUrl(url)
+	rule, err := h.ruleRepo.GetById(id)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("failed to parse url. %s", err.Error()))
+		AbortWithError(c, storageErrorStatus(err), "failed to get rule by id", err)
+		return
 	}
-	req, err := http.NewRequest(http.MethodGet, baseUrl+"/robots.txt", nil)
-	req.Header.Set("User-Agent", h.cfg.RuleUserAgent)
-	resp, err := h.httpClient.Do(req)
+
+	// the file is fetched from the site, the cached copy may be stale
+	tResp, err := h.evaluator.LiveRobotsTxt(c.Request.Context(), rule.Domain)
 	if err != nil {
-		slog.Error(fmt.Sprintf("error making http get request to %s/robots.txt", baseUrl),
-			slog.String("err", err.Error()))
-		return nil, err
+		_, message := engine.ClassifyFetchError(err, "robots.txt file")
+		AbortWithError(c, engine.FetchErrorStatus(err), "failed to fetch live robots.txt", errors.New(message))
+		return
 	}
-	defer func() {
-		err = resp.Body.Close()
-		if err != nil {
-			slog.Error("error closing response body", slog.String("err", err.Error()))
+	// a missing live robots.txt is compared as an empty file, the verdicts follow the /crawl-allowed logic.
+	// The invalid file is already replaced by the InvalidRobotsTxt policy
+	liveRobotsTxt := ""
+	livePolicy := ""
+	if isSuccess(tResp.StatusCode) {
+		liveRobotsTxt = string(tResp.Body)
+		if tResp.Content != nil {
+			livePolicy = tResp.Content.Policy
 		}
-	}()
+	}
 
-	body, err := io.ReadAll(resp.Body)
+	result := model.RuleComparisonResponse{
+		RuleID:            rule.ID,
+		Domain:            rule.Domain,
+		Blocked:           rule.Blocked,
+		LiveStatusCode:    tResp.StatusCode,
+		LivePolicy:        livePolicy,
+		Verdicts:          make([]model.VerdictCompare, 0, len(samples.Urls)*len(samples.UserAgents)),
+		IdenticalVerdicts: true,
+	}
+	result.Diff, err = util.UnifiedDiff(rule.RobotsTxt, liveRobotsTxt, "custom rule", "live robots.txt")
 	if err != nil {
-		slog.Error("error reading response body", slog.String("err", err.Error()))
-		return nil, err
+		slog.Warn("failed to diff the custom rule with the live robots.txt.", slog.Int("rule_id", rule.ID),
+			slog.String("err", err.Error()))
+		result.DiffTooLarge = true
+	}
+	for _, url := range samples.Urls {
+		for _, userAgent := range samples.UserAgents {
+			customAllowed := grobotstxt.AgentAllowed(rule.RobotsTxt, userAgent, url)
+			liveAllowed := isSuccess(tResp.StatusCode) && grobotstxt.AgentAllowed(liveRobotsTxt, userAgent, url)
+			result.Verdicts = append(result.Verdicts, model.VerdictCompare{
+				Url:         url,
+				UserAgent:   userAgent,
+				CustomRule:  customAllowed,
+				LiveRobots:  liveAllowed,
+				IsDifferent: customAllowed != liveAllowed,
+			})
+			if customAllowed != liveAllowed {
+				result.IdenticalVerdicts = false
+			}
+		}
 	}
 
-	return &model.TargetResponse{
-		StatusCode: resp.StatusCode,
-		Body:       body,
-	}, nil
+	c.JSON(http.StatusOK, result)
+}
+
+func isValidId(id string) bool {
+	_, err := strconv.Atoi(id)
+	return err == nil
 }
 
 func isSuccess(statusCode int) bool {
 	return statusCode >= 200 && statusCode < 300
 }
+
+const (
+	defaultRulesLimit = 50
+	maxRulesLimit     = 500
+)
+
+// ruleCursor is the position after the last rule of the page. The sort key and the order are kept to reject the
+// cursor of another listing.
+type ruleCursor struct {
+	SortBy    string    `json:"s"`
+	Order     string    `json:"o"`
+	ID        int       `json:"id"`
+	Domain    string    `json:"d,omitempty"`
+	UpdatedAt time.Time `json:"u,omitzero"`
+}
+
+func encodeRuleCursor(filter *model.RuleFilter, last *model.Rule) string {
+	cursor := ruleCursor{SortBy: filter.SortBy, Order: filter.Order, ID: last.ID}
+	if filter.SortBy == model.RuleSortDomain {
+		cursor.Domain = last.Domain
+	} else {
+		cursor.UpdatedAt = last.UpdatedAt
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeRuleCursor returns the last rule of the previous page. The cursor must be made for the same sort and order.
+func decodeRuleCursor(value string, filter *model.RuleFilter) (*model.Rule, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	var cursor ruleCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.SortBy != filter.SortBy || cursor.Order != filter.Order {
+		return nil, errors.New("the cursor is made for another sort or order")
+	}
+	return &model.Rule{ID: cursor.ID, Domain: cursor.Domain, UpdatedAt: cursor.UpdatedAt.UTC()}, nil
+}
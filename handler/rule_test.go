@@ -1,31 +1,55 @@
 package handler
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	u "net/url"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/IliaW/rule-api/config"
 	cacheMock "github.com/IliaW/rule-api/internal/cache/mocks"
 	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/internal/persistence"
 	storageMock "github.com/IliaW/rule-api/internal/persistence/mocks"
+	"github.com/IliaW/rule-api/internal/ssrf"
 	"github.com/IliaW/rule-api/internal/telemetry"
+	"github.com/IliaW/rule-api/util"
+	"github.com/andybalholm/brotli"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+var testTime = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
 type mockRoundTripper struct {
 	response *http.Response
+	body     []byte
+	once     sync.Once
 }
 
+// RoundTrip returns a copy of the mocked response, so the handler can make several requests to the target.
 func (rt *mockRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	return rt.response, nil
+	rt.once.Do(func() {
+		rt.body, _ = io.ReadAll(rt.response.Body)
+	})
+	resp := *rt.response
+	resp.Body = io.NopCloser(bytes.NewReader(rt.body))
+	return &resp, nil
 }
 
 func Test_GetAllowedCrawl_Handler(t *testing.T) {
@@ -40,11 +64,14 @@ func Test_GetAllowedCrawl_Handler(t *testing.T) {
 		name                  string
 		url                   string
 		userAgent             string
+		purpose               string
 		robotsUserAgent       string
-		mockCachedRobotsFile  func() ([]byte, bool)
+		mockCachedRobotsFile  func() (*model.RobotsFile, bool)
 		mockStorageCustomRule func() (*model.Rule, error)
 		mockHttpResponseCode  int
 		mockHttpResponseBody  string
+		mockHttpContentType   string
+		mockHttpEncoding      string
 		expectedResponse      string
 		expectedStatusCode    int
 	}{
@@ -53,7 +80,7 @@ func Test_GetAllowedCrawl_Handler(t *testing.T) {
 			url:             "https://example.com/test",
 			userAgent:       "bot",
 			robotsUserAgent: "robots-bot",
-			mockCachedRobotsFile: func() ([]byte, bool) {
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
 				return nil, false
 			},
 			mockStorageCustomRule: func() (*model.Rule, error) {
@@ -61,15 +88,20 @@ func Test_GetAllowedCrawl_Handler(t *testing.T) {
 			},
 			mockHttpResponseCode: http.StatusOK,
 			mockHttpResponseBody: "User-agent: * \n Allow: /test",
-			expectedResponse:     "{\"is_allowed\":true,\"status_code\":200,\"error\":\"\"}",
-			expectedStatusCode:   http.StatusOK,
+			expectedResponse: "{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"registrable_domain\":\"example.com\"," +
+				"\"content\":{\"content_type\":\"text/plain; charset=utf-8\",\"charset\":\"utf-8\",\"bom\":false," +
+				"\"detected\":\"robots.txt\",\"valid\":true}," +
+				"\"source\":\"live\",\"robots_url\":\"https://example.com/robots.txt\"," +
+				"\"fetched_at\":\"2025-01-01T12:00:00Z\",\"expires_at\":\"2025-01-02T12:00:00Z\"}",
+			expectedStatusCode: http.StatusOK,
 		},
 		{
 			name:            "crawl disallowed",
 			url:             "https://example.com/test",
 			userAgent:       "bot",
 			robotsUserAgent: "robots-bot",
-			mockCachedRobotsFile: func() ([]byte, bool) {
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
 				return nil, false
 			},
 			mockStorageCustomRule: func() (*model.Rule, error) {
@@ -77,15 +109,58 @@ func Test_GetAllowedCrawl_Handler(t *testing.T) {
 			},
 			mockHttpResponseCode: http.StatusOK,
 			mockHttpResponseBody: "User-agent: * \n Disallow: /test",
-			expectedResponse:     "{\"is_allowed\":false,\"status_code\":200,\"error\":\"\"}",
-			expectedStatusCode:   http.StatusOK,
+			expectedResponse: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"registrable_domain\":\"example.com\"," +
+				"\"content\":{\"content_type\":\"text/plain; charset=utf-8\",\"charset\":\"utf-8\",\"bom\":false," +
+				"\"detected\":\"robots.txt\",\"valid\":true}," +
+				"\"source\":\"live\",\"robots_url\":\"https://example.com/robots.txt\"," +
+				"\"fetched_at\":\"2025-01-01T12:00:00Z\",\"expires_at\":\"2025-01-02T12:00:00Z\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:            "robots.txt not found",
+			url:             "https://example.com/test",
+			userAgent:       "bot",
+			robotsUserAgent: "robots-bot",
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
+				return nil, false
+			},
+			mockStorageCustomRule: func() (*model.Rule, error) {
+				return nil, errors.New("not found")
+			},
+			mockHttpResponseCode: http.StatusNotFound,
+			mockHttpResponseBody: "<html><body>Something went wrong</body></html>",
+			expectedResponse: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":404," +
+				"\"error\":\"robots.txt returned status 404\",\"error_code\":\"ROBOTS_4XX\"," +
+				"\"registrable_domain\":\"example.com\",\"source\":\"live\"," +
+				"\"robots_url\":\"https://example.com/robots.txt\",\"fetched_at\":\"2025-01-01T12:00:00Z\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:            "robots.txt server error",
+			url:             "https://example.com/test",
+			userAgent:       "bot",
+			robotsUserAgent: "robots-bot",
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
+				return nil, false
+			},
+			mockStorageCustomRule: func() (*model.Rule, error) {
+				return nil, errors.New("not found")
+			},
+			mockHttpResponseCode: http.StatusServiceUnavailable,
+			mockHttpResponseBody: "<html><body>Something went wrong</body></html>",
+			expectedResponse: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":503," +
+				"\"error\":\"robots.txt returned status 503\",\"error_code\":\"ROBOTS_5XX\"," +
+				"\"registrable_domain\":\"example.com\",\"source\":\"live\"," +
+				"\"robots_url\":\"https://example.com/robots.txt\",\"fetched_at\":\"2025-01-01T12:00:00Z\"}",
+			expectedStatusCode: http.StatusOK,
 		},
 		{
 			name:            "missed url in query",
 			url:             "",
 			userAgent:       "bot",
 			robotsUserAgent: "robots-bot",
-			mockCachedRobotsFile: func() ([]byte, bool) {
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
 				return nil, false
 			},
 			mockStorageCustomRule: func() (*model.Rule, error) {
@@ -93,7 +168,7 @@ func Test_GetAllowedCrawl_Handler(t *testing.T) {
 			},
 			mockHttpResponseCode: http.StatusOK,
 			mockHttpResponseBody: "User-agent: * \n Allow: /test",
-			expectedResponse:     "{\"is_allowed\":false,\"status_code\":400,\"error\":\"'url' query parameter is required\"}",
+			expectedResponse:     "{\"is_allowed\":false,\"blocked\":false,\"status_code\":400,\"error\":\"'url' query parameter is required\",\"error_code\":\"INVALID_URL\"}",
 			expectedStatusCode:   http.StatusBadRequest,
 		},
 		{
@@ -101,7 +176,7 @@ func Test_GetAllowedCrawl_Handler(t *testing.T) {
 			url:             "https://example.com/test",
 			userAgent:       "",
 			robotsUserAgent: "robots-bot",
-			mockCachedRobotsFile: func() ([]byte, bool) {
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
 				return nil, false
 			},
 			mockStorageCustomRule: func() (*model.Rule, error) {
@@ -109,7 +184,7 @@ func Test_GetAllowedCrawl_Handler(t *testing.T) {
 			},
 			mockHttpResponseCode: http.StatusOK,
 			mockHttpResponseBody: "User-agent: * \n Allow: /test",
-			expectedResponse:     "{\"is_allowed\":false,\"status_code\":400,\"error\":\"'user_agent' query parameter is required\"}\n",
+			expectedResponse:     "{\"is_allowed\":false,\"blocked\":false,\"status_code\":400,\"error\":\"'user_agent' query parameter is required\",\"error_code\":\"INVALID_REQUEST\"}",
 			expectedStatusCode:   http.StatusBadRequest,
 		},
 		{
@@ -117,7 +192,7 @@ func Test_GetAllowedCrawl_Handler(t *testing.T) {
 			url:             "https://example.com/test",
 			userAgent:       "bot",
 			robotsUserAgent: "robots-bot",
-			mockCachedRobotsFile: func() ([]byte, bool) {
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
 				return nil, false
 			},
 			mockStorageCustomRule: func() (*model.Rule, error) {
@@ -129,24 +204,163 @@ func Test_GetAllowedCrawl_Handler(t *testing.T) {
 			},
 			mockHttpResponseCode: http.StatusOK,
 			mockHttpResponseBody: "User-agent: * \n Disallow: /test",
-			expectedResponse:     "{\"is_allowed\":true,\"status_code\":200,\"error\":\"\"}",
-			expectedStatusCode:   http.StatusOK,
+			expectedResponse: "{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\",\"registrable_domain\":\"example.com\"," +
+				"\"source\":\"custom_rule\",\"rule_id\":1}",
+			expectedStatusCode: http.StatusOK,
 		},
 		{
 			name:            "robots.txt file exists in cache",
 			url:             "https://example.com/test",
 			userAgent:       "bot",
 			robotsUserAgent: "robots-bot",
-			mockCachedRobotsFile: func() ([]byte, bool) {
-				return []byte("User-agent: * \n Allow: /test"), true
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
+				return &model.RobotsFile{
+					StatusCode: http.StatusOK,
+					Url:        "https://example.com/robots.txt",
+					Body:       []byte("User-agent: * \n Allow: /test"),
+					FetchedAt:  testTime.Add(-time.Hour),
+					ExpiresAt:  testTime.Add(23 * time.Hour),
+				}, true
+			},
+			mockStorageCustomRule: func() (*model.Rule, error) {
+				return nil, errors.New("not found")
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "User-agent: * \n Disallow: /test",
+			expectedResponse: "{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\",\"registrable_domain\":\"example.com\"," +
+				"\"source\":\"cache\",\"robots_url\":\"https://example.com/robots.txt\"," +
+				"\"fetched_at\":\"2025-01-01T11:00:00Z\",\"expires_at\":\"2025-01-02T11:00:00Z\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:            "ai.txt verdict for ai-training purpose",
+			url:             "https://example.com/test",
+			userAgent:       "bot",
+			purpose:         "ai-training",
+			robotsUserAgent: "robots-bot",
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
+				return nil, false
 			},
 			mockStorageCustomRule: func() (*model.Rule, error) {
 				return nil, errors.New("not found")
 			},
 			mockHttpResponseCode: http.StatusOK,
 			mockHttpResponseBody: "User-agent: * \n Disallow: /test",
-			expectedResponse:     "{\"is_allowed\":true,\"status_code\":200,\"error\":\"\"}",
-			expectedStatusCode:   http.StatusOK,
+			expectedResponse: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"ai_txt\":{\"is_allowed\":false,\"found\":true,\"status_code\":200}," +
+				"\"token_verdicts\":[{\"token\":\"bot\",\"is_allowed\":false},{\"token\":\"GPTBot\",\"is_allowed\":false}]," +
+				"\"registrable_domain\":\"example.com\"," +
+				"\"content\":{\"content_type\":\"text/plain; charset=utf-8\",\"charset\":\"utf-8\",\"bom\":false," +
+				"\"detected\":\"robots.txt\",\"valid\":true}," +
+				"\"source\":\"live\",\"robots_url\":\"https://example.com/robots.txt\"," +
+				"\"fetched_at\":\"2025-01-01T12:00:00Z\",\"expires_at\":\"2025-01-02T12:00:00Z\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:            "purpose token is disallowed",
+			url:             "https://example.com/test",
+			userAgent:       "bot",
+			purpose:         "search-indexing",
+			robotsUserAgent: "robots-bot",
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
+				return nil, false
+			},
+			mockStorageCustomRule: func() (*model.Rule, error) {
+				return nil, errors.New("not found")
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "User-agent: * \n Allow: /test \n\nUser-agent: Googlebot \n Disallow: /test",
+			expectedResponse: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"token_verdicts\":[{\"token\":\"bot\",\"is_allowed\":true},{\"token\":\"Googlebot\",\"is_allowed\":false}]," +
+				"\"registrable_domain\":\"example.com\"," +
+				"\"content\":{\"content_type\":\"text/plain; charset=utf-8\",\"charset\":\"utf-8\",\"bom\":false," +
+				"\"detected\":\"robots.txt\",\"valid\":true}," +
+				"\"source\":\"live\",\"robots_url\":\"https://example.com/robots.txt\"," +
+				"\"fetched_at\":\"2025-01-01T12:00:00Z\",\"expires_at\":\"2025-01-02T12:00:00Z\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:            "unsupported purpose",
+			url:             "https://example.com/test",
+			userAgent:       "bot",
+			purpose:         "unknown",
+			robotsUserAgent: "robots-bot",
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
+				return nil, false
+			},
+			mockStorageCustomRule: func() (*model.Rule, error) {
+				return nil, errors.New("not found")
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "User-agent: * \n Allow: /test",
+			expectedResponse: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":400," +
+				"\"error\":\"unsupported 'purpose' query parameter 'unknown'\",\"error_code\":\"INVALID_REQUEST\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:            "html page instead of robots.txt",
+			url:             "https://example.com/test",
+			userAgent:       "bot",
+			robotsUserAgent: "robots-bot",
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
+				return nil, false
+			},
+			mockStorageCustomRule: func() (*model.Rule, error) {
+				return nil, errors.New("not found")
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "<!DOCTYPE html><html><body>Disallow: /</body></html>",
+			mockHttpContentType:  "text/html",
+			expectedResponse: "{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"registrable_domain\":\"example.com\",\"content\":{\"content_type\":\"text/html\",\"charset\":\"utf-8\"," +
+				"\"bom\":false,\"detected\":\"html\",\"valid\":false,\"policy\":\"allow_all\"}," +
+				"\"source\":\"live\",\"robots_url\":\"https://example.com/robots.txt\"," +
+				"\"fetched_at\":\"2025-01-01T12:00:00Z\",\"expires_at\":\"2025-01-02T12:00:00Z\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:            "utf-16 robots.txt with bom",
+			url:             "https://example.com/test",
+			userAgent:       "bot",
+			robotsUserAgent: "robots-bot",
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
+				return nil, false
+			},
+			mockStorageCustomRule: func() (*model.Rule, error) {
+				return nil, errors.New("not found")
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "\xff\xfe" + utf16le("User-agent: * \n Disallow: /test"),
+			mockHttpContentType:  "text/plain",
+			expectedResponse: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"registrable_domain\":\"example.com\",\"content\":{\"content_type\":\"text/plain\"," +
+				"\"charset\":\"utf-16le\",\"bom\":true,\"detected\":\"robots.txt\",\"valid\":true}," +
+				"\"source\":\"live\",\"robots_url\":\"https://example.com/robots.txt\"," +
+				"\"fetched_at\":\"2025-01-01T12:00:00Z\",\"expires_at\":\"2025-01-02T12:00:00Z\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:            "brotli compressed robots.txt",
+			url:             "https://example.com/test",
+			userAgent:       "bot",
+			robotsUserAgent: "robots-bot",
+			mockCachedRobotsFile: func() (*model.RobotsFile, bool) {
+				return nil, false
+			},
+			mockStorageCustomRule: func() (*model.Rule, error) {
+				return nil, errors.New("not found")
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: brotliString("User-agent: * \n Disallow: /test"),
+			mockHttpContentType:  "text/plain",
+			mockHttpEncoding:     "br",
+			expectedResponse: "{\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\"," +
+				"\"registrable_domain\":\"example.com\",\"content\":{\"content_type\":\"text/plain\"," +
+				"\"content_encoding\":\"br\",\"charset\":\"utf-8\",\"bom\":false,\"detected\":\"robots.txt\"," +
+				"\"valid\":true}," +
+				"\"source\":\"live\",\"robots_url\":\"https://example.com/robots.txt\"," +
+				"\"fetched_at\":\"2025-01-01T12:00:00Z\",\"expires_at\":\"2025-01-02T12:00:00Z\"}",
+			expectedStatusCode: http.StatusOK,
 		},
 	}
 	for _, test := range testSet {
@@ -154,6 +368,13 @@ func Test_GetAllowedCrawl_Handler(t *testing.T) {
 			// mock config
 			cfg := &config.Config{
 				RuleUserAgent: test.robotsUserAgent,
+				CacheSettings: &config.CacheConfig{
+					TtlForRobotsTxt: 24 * time.Hour,
+				},
+				CrawlPurposes: map[string][]string{
+					"ai-training":     {"GPTBot"},
+					"search-indexing": {"Googlebot"},
+				},
 				TelemetrySettings: &config.TelemetryConfig{
 					Enabled: false,
 				},
@@ -162,21 +383,30 @@ func Test_GetAllowedCrawl_Handler(t *testing.T) {
 			cache := cacheMock.NewCachedClient(tt)
 			cache.On("GetRobotsFile", mock.Anything).Maybe().Return(test.mockCachedRobotsFile())
 			cache.On("SaveRobotsFile", mock.Anything, mock.Anything).Maybe()
+			cache.On("GetAiTxtFile", mock.Anything).Maybe().Return(nil, false)
+			cache.On("SaveAiTxtFile", mock.Anything, mock.Anything).Maybe()
 			// mock storage
 			ruleRepo := storageMock.NewRuleStorage(tt)
 			ruleRepo.On("GetByUrl", mock.Anything).Maybe().Return(test.mockStorageCustomRule())
 			// mock http client
 			httpMock := httptest.NewRecorder()
+			if test.mockHttpContentType != "" {
+				httpMock.Header().Set("Content-Type", test.mockHttpContentType)
+			}
+			if test.mockHttpEncoding != "" {
+				httpMock.Header().Set("Content-Encoding", test.mockHttpEncoding)
+			}
 			httpMock.WriteString(test.mockHttpResponseBody)
 			httpMock.Code = test.mockHttpResponseCode
 			expectedRobotsTxt := httpMock.Result()
-			httpClient := &http.Client{Transport: &mockRoundTripper{expectedRobotsTxt}}
+			httpClient := &http.Client{Transport: &mockRoundTripper{response: expectedRobotsTxt}}
 
 			r := gin.Default()
 			robotsHandler := NewRuleApiHandler(cfg, cache, ruleRepo, httpClient, metrics.ApiMetrics)
+			robotsHandler.now = func() time.Time { return testTime }
 			r.GET("/crawl-allowed", robotsHandler.GetAllowedCrawl)
-			req, _ := http.NewRequest("GET", fmt.Sprintf("/crawl-allowed?url=%s&user_agent=%s",
-				test.url, test.userAgent), nil)
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/crawl-allowed?url=%s&user_agent=%s&purpose=%s",
+				test.url, test.userAgent, test.purpose), nil)
 			w := httptest.NewRecorder()
 			r.ServeHTTP(w, req)
 
@@ -215,8 +445,8 @@ func Test_GetCustomRule_Handler(t *testing.T) {
 				}, nil
 			},
 			mockMethodName: "GetByUrl",
-			expectedResponse: "{\"id\":1,\"domain\":\"example.com\",\"robots_txt\":\"User-agent: * \\n Allow: " +
-				"/test\",\"blocked\":false,\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"0001-01-01T00:00:00Z\"}",
+			expectedResponse: "{\"id\":1,\"domain\":\"example.com\",\"blocked\":false,\"robots_txt\":\"User-agent: * " +
+				"\\n Allow: /test\",\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"0001-01-01T00:00:00Z\"}",
 			expectedStatusCode: http.StatusOK,
 		},
 		{
@@ -224,10 +454,11 @@ func Test_GetCustomRule_Handler(t *testing.T) {
 			id:   "",
 			url:  "https://example1.com/test",
 			mockStorage: func() (*model.Rule, error) {
-				return nil, errors.New("rule with domain 'example1.com' not found")
+				return nil, fmt.Errorf("rule with domain 'example1.com' %w", persistence.ErrNotFound)
 			},
-			mockMethodName:     "GetByUrl",
-			expectedResponse:   "{\"error\":\"failed to get rule by url. rule with domain 'example1.com' not found\"}",
+			mockMethodName: "GetByUrl",
+			expectedResponse: "{\"code\":\"NOT_FOUND\",\"message\":\"failed to get rule by url\"," +
+				"\"details\":\"rule with domain 'example1.com' not found\"}",
 			expectedStatusCode: http.StatusNotFound,
 		},
 		{
@@ -242,8 +473,8 @@ func Test_GetCustomRule_Handler(t *testing.T) {
 				}, nil
 			},
 			mockMethodName: "GetById",
-			expectedResponse: "{\"id\":1,\"domain\":\"example.com\",\"robots_txt\":\"User-agent: * \\n Allow: " +
-				"/test\",\"blocked\":false,\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"0001-01-01T00:00:00Z\"}",
+			expectedResponse: "{\"id\":1,\"domain\":\"example.com\",\"blocked\":false,\"robots_txt\":\"User-agent: * " +
+				"\\n Allow: /test\",\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"0001-01-01T00:00:00Z\"}",
 			expectedStatusCode: http.StatusOK,
 		},
 		{
@@ -254,7 +485,7 @@ func Test_GetCustomRule_Handler(t *testing.T) {
 				return nil, nil
 			},
 			mockMethodName:     "GetById",
-			expectedResponse:   "{\"error\":\"'id' or 'url' query parameter is required\"}",
+			expectedResponse:   "{\"code\":\"BAD_REQUEST\",\"message\":\"'id' or 'url' query parameter is required\"}",
 			expectedStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -262,10 +493,11 @@ func Test_GetCustomRule_Handler(t *testing.T) {
 			id:   "2",
 			url:  "",
 			mockStorage: func() (*model.Rule, error) {
-				return nil, errors.New("rule with id '2' not found")
+				return nil, fmt.Errorf("rule with id '2' %w", persistence.ErrNotFound)
 			},
-			mockMethodName:     "GetById",
-			expectedResponse:   "{\"error\":\"failed to get rule by id. rule with id '2' not found\"}",
+			mockMethodName: "GetById",
+			expectedResponse: "{\"code\":\"NOT_FOUND\",\"message\":\"failed to get rule by id\"," +
+				"\"details\":\"rule with id '2' not found\"}",
 			expectedStatusCode: http.StatusNotFound,
 		},
 	}
@@ -290,6 +522,136 @@ func Test_GetCustomRule_Handler(t *testing.T) {
 	}
 }
 
+func Test_GetCustomRules_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := telemetry.SetupMetrics(context.Background(), &config.Config{
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	})
+	updatedAt := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	rules := []*model.Rule{
+		{ID: 1, Domain: "a.com", RobotsTxt: "User-agent: *", UpdatedAt: updatedAt.Add(time.Hour)},
+		{ID: 2, Domain: "b.com", RobotsTxt: "User-agent: *", UpdatedAt: updatedAt},
+		{ID: 3, Domain: "c.com", RobotsTxt: "User-agent: *", UpdatedAt: updatedAt.Add(-time.Hour)},
+	}
+	testSet := []struct {
+		name               string
+		query              string
+		mockFilter         func(filter *model.RuleFilter) bool
+		mockRules          []*model.Rule
+		mockErr            error
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name:  "first page",
+			query: "limit=2",
+			mockFilter: func(filter *model.RuleFilter) bool {
+				return filter.SortBy == model.RuleSortUpdatedAt && filter.Order == model.SortDesc &&
+					filter.Limit == 3 && filter.After == nil && filter.Blocked == nil
+			},
+			mockRules: rules,
+			expectedResponse: "{\"rules\":[{\"id\":1,\"domain\":\"a.com\",\"blocked\":false,\"robots_txt\":\"User-agent: *\"," +
+				"\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"2025-01-02T13:00:00Z\"}," +
+				"{\"id\":2,\"domain\":\"b.com\",\"blocked\":false,\"robots_txt\":\"User-agent: *\"," +
+				"\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"2025-01-02T12:00:00Z\"}]," +
+				"\"next_cursor\":\"eyJzIjoidXBkYXRlZF9hdCIsIm8iOiJkZXNjIiwiaWQiOjIsInUiOiIyMDI1LTAxLTAyVDEyOjAwOjAwWiJ9\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "filters and cursor",
+			query: "domain=c.co&robots_txt=agent&blocked=false&created_from=2025-01-01T00:00:00Z&sort=domain" +
+				"&cursor=eyJzIjoiZG9tYWluIiwibyI6ImFzYyIsImlkIjo1LCJkIjoiZXhhbXBsZS5jb20ifQ",
+			mockFilter: func(filter *model.RuleFilter) bool {
+				return filter.Domain == "c.co" && filter.RobotsTxt == "agent" && !*filter.Blocked &&
+					filter.CreatedFrom.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) && filter.CreatedTo == nil &&
+					filter.SortBy == model.RuleSortDomain && filter.Order == model.SortAsc && filter.Limit == 51 &&
+					filter.After.ID == 5 && filter.After.Domain == "example.com"
+			},
+			mockRules: rules[2:],
+			expectedResponse: "{\"rules\":[{\"id\":3,\"domain\":\"c.com\",\"blocked\":false,\"robots_txt\":\"User-agent: *\"," +
+				"\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"2025-01-02T11:00:00Z\"}]}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:  "date filters with offset",
+			query: "updated_from=2025-01-02T14:00:00%2B02:00&updated_to=2025-01-02T08:00:00-05:00",
+			mockFilter: func(filter *model.RuleFilter) bool {
+				return filter.UpdatedFrom.Location() == time.UTC && filter.UpdatedTo.Location() == time.UTC &&
+					filter.UpdatedFrom.Equal(time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)) &&
+					filter.UpdatedTo.Equal(time.Date(2025, 1, 2, 13, 0, 0, 0, time.UTC))
+			},
+			mockRules: rules[1:2],
+			expectedResponse: "{\"rules\":[{\"id\":2,\"domain\":\"b.com\",\"blocked\":false,\"robots_txt\":\"User-agent: *\"," +
+				"\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"2025-01-02T12:00:00Z\"}]}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "no rules",
+			query:              "order=asc",
+			mockFilter:         func(filter *model.RuleFilter) bool { return filter.Order == model.SortAsc },
+			mockRules:          []*model.Rule{},
+			expectedResponse:   "{\"rules\":[]}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "invalid sort",
+			query:              "sort=id",
+			expectedResponse:   "{\"code\":\"BAD_REQUEST\",\"message\":\"'sort' query parameter must be 'updated_at' or 'domain'\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid limit",
+			query: "limit=501",
+			expectedResponse: "{\"code\":\"BAD_REQUEST\"," +
+				"\"message\":\"'limit' query parameter must be an integer from 1 to 500\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid date",
+			query:              "updated_to=2025-01-01",
+			expectedResponse:   "{\"code\":\"BAD_REQUEST\",\"message\":\"'updated_to' query parameter must be a RFC 3339 time\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "cursor of another sort",
+			query: "cursor=eyJzIjoiZG9tYWluIiwibyI6ImFzYyIsImlkIjo1LCJkIjoiZXhhbXBsZS5jb20ifQ",
+			expectedResponse: "{\"code\":\"BAD_REQUEST\",\"message\":\"invalid 'cursor' query parameter\"," +
+				"\"details\":\"the cursor is made for another sort or order\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "database error",
+			query:              "",
+			mockFilter:         func(*model.RuleFilter) bool { return true },
+			mockErr:            errors.New("connection refused"),
+			expectedResponse:   "{\"code\":\"INTERNAL_ERROR\",\"message\":\"failed to list rules\"}",
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			// mock storage
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			if test.mockFilter != nil {
+				ruleRepo.On("List", mock.MatchedBy(test.mockFilter)).Once().Return(test.mockRules, test.mockErr)
+			}
+
+			r := gin.Default()
+			robotsHandler := NewRuleApiHandler(nil, nil, ruleRepo, nil, metrics.ApiMetrics)
+			r.GET("/custom-rules", robotsHandler.GetCustomRules)
+			req, _ := http.NewRequest("GET", "/custom-rules?"+test.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			responseData, _ := io.ReadAll(w.Body)
+			assert.Equal(tt, test.expectedResponse, string(responseData))
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
 func Test_CreateCustomRule_Handler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	metrics := telemetry.SetupMetrics(context.Background(), &config.Config{
@@ -325,7 +687,7 @@ func Test_CreateCustomRule_Handler(t *testing.T) {
 				return 1, nil
 			},
 			mockMethodName:     "Save",
-			expectedResponse:   "{\"error\":\"'url' query parameter is required\"}",
+			expectedResponse:   "{\"code\":\"BAD_REQUEST\",\"message\":\"'url' query parameter is required\"}",
 			expectedStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -336,19 +698,32 @@ func Test_CreateCustomRule_Handler(t *testing.T) {
 				return 1, nil
 			},
 			mockMethodName:     "Save",
-			expectedResponse:   "{\"error\":\"custom rules are not found or empty\"}",
+			expectedResponse:   "{\"code\":\"BAD_REQUEST\",\"message\":\"custom rules are not found or empty\"}",
 			expectedStatusCode: http.StatusBadRequest,
 		},
 		{
-			name: "error when save custom rule to database",
+			name: "create custom rule with too long domain",
+			url:  "https://" + strings.Repeat("a.", 126) + "com/test",
+			body: "User-agent: * \n Allow: /test",
+			mockStorage: func() (int64, error) {
+				return 1, nil
+			},
+			mockMethodName: "Save",
+			expectedResponse: "{\"code\":\"UNPROCESSABLE_ENTITY\"," +
+				"\"message\":\"the domain must not be longer than 253 characters\"}",
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "custom rule for the domain already exists",
 			url:  "https://example.com/test",
 			body: "User-agent: * \n Allow: /test",
 			mockStorage: func() (int64, error) {
-				return 0, errors.New("duplicate entry")
+				return 0, fmt.Errorf("rule with domain 'example.com' %w", persistence.ErrDuplicate)
 			},
-			mockMethodName:     "Save",
-			expectedResponse:   "{\"error\":\"failed to save custom rule. duplicate entry\"}",
-			expectedStatusCode: http.StatusInternalServerError,
+			mockMethodName: "Save",
+			expectedResponse: "{\"code\":\"CONFLICT\",\"message\":\"failed to save custom rule\"," +
+				"\"details\":\"rule with domain 'example.com' already exists\"}",
+			expectedStatusCode: http.StatusConflict,
 		},
 	}
 	for _, test := range testSet {
@@ -414,8 +789,8 @@ func Test_UpdateCustomRule_Handler(t *testing.T) {
 					RobotsTxt: "User-agent: * \n Disallow: /test",
 				}, nil
 			},
-			expectedResponse: "{\"id\":1,\"domain\":\"example.com\",\"robots_txt\":\"User-agent: * " +
-				"\\n Disallow: /test\",\"blocked\":false,\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"0001-01-01T00:00:00Z\"}",
+			expectedResponse: "{\"id\":1,\"domain\":\"example.com\",\"blocked\":false,\"robots_txt\":\"User-agent: * " +
+				"\\n Disallow: /test\",\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"0001-01-01T00:00:00Z\"}",
 			expectedStatusCode: http.StatusOK,
 		},
 		{
@@ -441,8 +816,8 @@ func Test_UpdateCustomRule_Handler(t *testing.T) {
 					RobotsTxt: "User-agent: * \n Disallow: /test",
 				}, nil
 			},
-			expectedResponse: "{\"id\":1,\"domain\":\"example.com\",\"robots_txt\":\"User-agent: * " +
-				"\\n Disallow: /test\",\"blocked\":false,\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"0001-01-01T00:00:00Z\"}",
+			expectedResponse: "{\"id\":1,\"domain\":\"example.com\",\"blocked\":false,\"robots_txt\":\"User-agent: * " +
+				"\\n Disallow: /test\",\"created_at\":\"0001-01-01T00:00:00Z\",\"updated_at\":\"0001-01-01T00:00:00Z\"}",
 			expectedStatusCode: http.StatusOK,
 		},
 		{
@@ -460,7 +835,7 @@ func Test_UpdateCustomRule_Handler(t *testing.T) {
 			mockUpdateStorageRequest: func() (*model.Rule, error) {
 				return &model.Rule{}, nil
 			},
-			expectedResponse:   "{\"error\":\"'id' or 'url' query parameter is required\"}",
+			expectedResponse:   "{\"code\":\"BAD_REQUEST\",\"message\":\"'id' or 'url' query parameter is required\"}",
 			expectedStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -478,7 +853,26 @@ func Test_UpdateCustomRule_Handler(t *testing.T) {
 			mockUpdateStorageRequest: func() (*model.Rule, error) {
 				return &model.Rule{}, nil
 			},
-			expectedResponse:   "{\"error\":\"'blocked' query parameter is required\"}",
+			expectedResponse:   "{\"code\":\"BAD_REQUEST\",\"message\":\"'blocked' query parameter is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:    "unparsable blocked query parameter",
+			id:      "1",
+			url:     "",
+			blocked: "maybe",
+			body:    "User-agent: * \n Disallow: /test",
+			mockGetByIdStorageRequest: func() (*model.Rule, error) {
+				return &model.Rule{}, nil
+			},
+			mockGetByUrlStorageRequest: func() (*model.Rule, error) {
+				return &model.Rule{}, nil
+			},
+			mockUpdateStorageRequest: func() (*model.Rule, error) {
+				return &model.Rule{}, nil
+			},
+			expectedResponse: "{\"code\":\"BAD_REQUEST\",\"message\":\"unable to parse 'blocked' query parameter\"," +
+				"\"details\":\"strconv.ParseBool: parsing \\\"maybe\\\": invalid syntax\"}",
 			expectedStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -488,7 +882,7 @@ func Test_UpdateCustomRule_Handler(t *testing.T) {
 			blocked: "false",
 			body:    "User-agent: * \n Disallow: /test",
 			mockGetByIdStorageRequest: func() (*model.Rule, error) {
-				return nil, errors.New("rule with id '2' not found")
+				return nil, fmt.Errorf("rule with id '2' %w", persistence.ErrNotFound)
 			},
 			mockGetByUrlStorageRequest: func() (*model.Rule, error) {
 				return &model.Rule{}, nil
@@ -496,7 +890,8 @@ func Test_UpdateCustomRule_Handler(t *testing.T) {
 			mockUpdateStorageRequest: func() (*model.Rule, error) {
 				return &model.Rule{}, nil
 			},
-			expectedResponse:   "{\"error\":\"failed to get rule by id. rule with id '2' not found\"}",
+			expectedResponse: "{\"code\":\"NOT_FOUND\",\"message\":\"failed to get rule by id\"," +
+				"\"details\":\"rule with id '2' not found\"}",
 			expectedStatusCode: http.StatusNotFound,
 		},
 		{
@@ -509,12 +904,13 @@ func Test_UpdateCustomRule_Handler(t *testing.T) {
 				return &model.Rule{}, nil
 			},
 			mockGetByUrlStorageRequest: func() (*model.Rule, error) {
-				return nil, errors.New("rule with domain 'example.com' not found")
+				return nil, fmt.Errorf("rule with domain 'example.com' %w", persistence.ErrNotFound)
 			},
 			mockUpdateStorageRequest: func() (*model.Rule, error) {
 				return &model.Rule{}, nil
 			},
-			expectedResponse:   "{\"error\":\"failed to get rule by url. rule with domain 'example.com' not found\"}",
+			expectedResponse: "{\"code\":\"NOT_FOUND\",\"message\":\"failed to get rule by url\"," +
+				"\"details\":\"rule with domain 'example.com' not found\"}",
 			expectedStatusCode: http.StatusNotFound,
 		},
 		{
@@ -536,7 +932,7 @@ func Test_UpdateCustomRule_Handler(t *testing.T) {
 			mockUpdateStorageRequest: func() (*model.Rule, error) {
 				return nil, errors.New("something went wrong")
 			},
-			expectedResponse:   "{\"error\":\"failed to update custom rule. something went wrong\"}",
+			expectedResponse:   "{\"code\":\"INTERNAL_ERROR\",\"message\":\"failed to update custom rule\"}",
 			expectedStatusCode: http.StatusInternalServerError,
 		},
 	}
@@ -589,21 +985,29 @@ func Test_DeleteCustomRule_Handler(t *testing.T) {
 			name:                      "id query parameter is empty",
 			id:                        "",
 			mockDeleteStorageResponse: nil,
-			expectedResponse:          "{\"error\":\"'id' query parameter is required\"}",
+			expectedResponse:          "{\"code\":\"BAD_REQUEST\",\"message\":\"'id' query parameter is required\"}",
 			expectedStatusCode:        http.StatusBadRequest,
 		},
 		{
 			name:                      "delete custom rule with non-existent id",
-			id:                        "1",
+			id:                        "2",
+			mockDeleteStorageResponse: fmt.Errorf("rule with id '2' %w", persistence.ErrNotFound),
+			expectedResponse: "{\"code\":\"NOT_FOUND\",\"message\":\"failed to delete custom rule\"," +
+				"\"details\":\"rule with id '2' not found\"}",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:                      "id query parameter is not an integer",
+			id:                        "abc",
 			mockDeleteStorageResponse: nil,
-			expectedResponse:          "{\"message\":\"rule with id '1' is deleted\"}",
-			expectedStatusCode:        http.StatusOK,
+			expectedResponse:          "{\"code\":\"BAD_REQUEST\",\"message\":\"'id' query parameter must be an integer\"}",
+			expectedStatusCode:        http.StatusBadRequest,
 		},
 		{
 			name:                      "error when delete custom rule",
 			id:                        "1",
 			mockDeleteStorageResponse: errors.New("something went wrong"),
-			expectedResponse:          "{\"error\":\"failed to delete custom rule. something went wrong\"}",
+			expectedResponse:          "{\"code\":\"INTERNAL_ERROR\",\"message\":\"failed to delete custom rule\"}",
 			expectedStatusCode:        http.StatusInternalServerError,
 		},
 	}
@@ -626,3 +1030,971 @@ func Test_DeleteCustomRule_Handler(t *testing.T) {
 		})
 	}
 }
+
+func Test_CompareCustomRule_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := telemetry.SetupMetrics(context.Background(), &config.Config{
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	})
+	testSet := []struct {
+		name                 string
+		id                   string
+		body                 string
+		mockGetById          func() (*model.Rule, error)
+		mockHttpResponseCode int
+		mockHttpResponseBody string
+		invalidRobotsTxt     string
+		expectedResponse     string
+		expectedStatusCode   int
+	}{
+		{
+			name: "custom rule differs from live robots.txt",
+			id:   "1",
+			body: "{\"urls\":[\"https://example.com/test\"],\"user_agents\":[\"bot\"]}",
+			mockGetById: func() (*model.Rule, error) {
+				return &model.Rule{
+					ID:        1,
+					Domain:    "example.com",
+					RobotsTxt: "User-agent: *\nAllow: /test",
+				}, nil
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "User-agent: *\nDisallow: /test",
+			expectedResponse: "{\"rule_id\":1,\"domain\":\"example.com\",\"blocked\":false,\"live_status_code\":200," +
+				"\"verdicts\":[{\"url\":\"https://example.com/test\",\"user_agent\":\"bot\",\"custom_rule_allowed\":true," +
+				"\"live_allowed\":false,\"is_different\":true}],\"identical_verdicts\":false," +
+				"\"diff\":\"--- custom rule\\n+++ live robots.txt\\n@@ -1,2 +1,2 @@\\n User-agent: *\\n-Allow: /test\\n" +
+				"+Disallow: /test\\n\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "custom rule matches live robots.txt",
+			id:   "1",
+			body: "{\"urls\":[\"https://example.com/test\"],\"user_agents\":[\"bot\"]}",
+			mockGetById: func() (*model.Rule, error) {
+				return &model.Rule{
+					ID:        1,
+					Domain:    "example.com",
+					RobotsTxt: "User-agent: *\nAllow: /test",
+				}, nil
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "User-agent: *\r\nAllow: /test\r\n",
+			expectedResponse: "{\"rule_id\":1,\"domain\":\"example.com\",\"blocked\":false,\"live_status_code\":200," +
+				"\"verdicts\":[{\"url\":\"https://example.com/test\",\"user_agent\":\"bot\",\"custom_rule_allowed\":true," +
+				"\"live_allowed\":true,\"is_different\":false}],\"identical_verdicts\":true,\"diff\":\"\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "invalid live robots.txt is replaced by the policy",
+			id:   "1",
+			body: "{\"urls\":[\"https://example.com/test\"],\"user_agents\":[\"bot\"]}",
+			mockGetById: func() (*model.Rule, error) {
+				return &model.Rule{
+					ID:        1,
+					Domain:    "example.com",
+					RobotsTxt: "User-agent: *\nAllow: /test",
+				}, nil
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "<html><body>Not found</body></html>",
+			invalidRobotsTxt:     "disallow_all",
+			expectedResponse: "{\"rule_id\":1,\"domain\":\"example.com\",\"blocked\":false,\"live_status_code\":200," +
+				"\"live_policy\":\"disallow_all\",\"verdicts\":[{\"url\":\"https://example.com/test\"," +
+				"\"user_agent\":\"bot\",\"custom_rule_allowed\":true,\"live_allowed\":false,\"is_different\":true}]," +
+				"\"identical_verdicts\":false,\"diff\":\"--- custom rule\\n+++ live robots.txt\\n@@ -1,2 +1,2 @@\\n" +
+				" User-agent: *\\n-Allow: /test\\n+Disallow: /\\n\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "html live robots.txt allows all by default",
+			id:   "1",
+			body: "{\"urls\":[\"https://example.com/test\"],\"user_agents\":[\"bot\"]}",
+			mockGetById: func() (*model.Rule, error) {
+				return &model.Rule{
+					ID:        1,
+					Domain:    "example.com",
+					RobotsTxt: "User-agent: *\nDisallow: /test",
+				}, nil
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "<html><body>Not found</body></html>",
+			expectedResponse: "{\"rule_id\":1,\"domain\":\"example.com\",\"blocked\":false,\"live_status_code\":200," +
+				"\"live_policy\":\"allow_all\",\"verdicts\":[{\"url\":\"https://example.com/test\"," +
+				"\"user_agent\":\"bot\",\"custom_rule_allowed\":false,\"live_allowed\":true,\"is_different\":true}]," +
+				"\"identical_verdicts\":false,\"diff\":\"--- custom rule\\n+++ live robots.txt\\n@@ -1,2 +1,2 @@\\n" +
+				" User-agent: *\\n-Disallow: /test\\n+Allow: /\\n\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "empty samples",
+			id:   "1",
+			body: "{\"urls\":[],\"user_agents\":[\"bot\"]}",
+			mockGetById: func() (*model.Rule, error) {
+				return &model.Rule{}, nil
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "",
+			expectedResponse: "{\"code\":\"BAD_REQUEST\"," +
+				"\"message\":\"'urls' and 'user_agents' must not be empty\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "non-existent id in query",
+			id:   "2",
+			body: "{\"urls\":[\"https://example.com/test\"],\"user_agents\":[\"bot\"]}",
+			mockGetById: func() (*model.Rule, error) {
+				return nil, fmt.Errorf("rule with id '2' %w", persistence.ErrNotFound)
+			},
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "",
+			expectedResponse: "{\"code\":\"NOT_FOUND\",\"message\":\"failed to get rule by id\"," +
+				"\"details\":\"rule with id '2' not found\"}",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cfg := &config.Config{
+				RuleUserAgent:    "robots-bot",
+				InvalidRobotsTxt: test.invalidRobotsTxt,
+				TelemetrySettings: &config.TelemetryConfig{
+					Enabled: false,
+				},
+			}
+			// mock cache. The stale cached file is neither used nor replaced, the live file is fetched
+			cache := cacheMock.NewCachedClient(tt)
+			cache.On("GetRobotsFile", mock.Anything).Maybe().Return(&model.RobotsFile{StatusCode: http.StatusOK,
+				Body: []byte("User-agent: *\nDisallow: /\n")}, true)
+			// mock storage
+			ruleRepo := storageMock.NewRuleStorage(tt)
+			ruleRepo.On("GetById", mock.Anything).Maybe().Return(test.mockGetById())
+			// mock http client
+			httpMock := httptest.NewRecorder()
+			httpMock.WriteString(test.mockHttpResponseBody)
+			httpMock.Code = test.mockHttpResponseCode
+			httpClient := &http.Client{Transport: &mockRoundTripper{response: httpMock.Result()}}
+
+			r := gin.Default()
+			robotsHandler := NewRuleApiHandler(cfg, cache, ruleRepo, httpClient, metrics.ApiMetrics)
+			r.POST("/custom-rule/compare", robotsHandler.CompareCustomRule)
+			req, _ := http.NewRequest("POST", fmt.Sprintf("/custom-rule/compare?id=%s", test.id),
+				strings.NewReader(test.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			responseData, _ := io.ReadAll(w.Body)
+			assert.Equal(tt, test.expectedResponse, string(responseData))
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+			cache.AssertNotCalled(tt, "GetRobotsFile", mock.Anything)
+			cache.AssertNotCalled(tt, "SaveRobotsFile", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func Test_GetTdmReservation_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := telemetry.SetupMetrics(context.Background(), &config.Config{
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	})
+	testSet := []struct {
+		name                 string
+		url                  string
+		mockHttpResponseCode int
+		mockHttpResponseBody string
+		mockHttpHeaders      map[string]string
+		mockHttpError        error
+		mockCachedFile       string
+		expectedCachedFile   string
+		expectedResponse     string
+		expectedStatusCode   int
+	}{
+		{
+			name:                 "reservation from tdmrep.json",
+			url:                  "https://example.com/articles/1",
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "[{\"location\":\"/images/*\",\"tdm-reservation\":0}," +
+				"{\"location\":\"/articles/*\",\"tdm-reservation\":1,\"tdm-policy\":\"https://example.com/policy.json\"}]",
+			expectedResponse: "{\"url\":\"https://example.com/articles/1\",\"reservation\":true," +
+				"\"policy\":\"https://example.com/policy.json\",\"source\":\"tdmrep.json\",\"location\":\"/articles/*\"," +
+				"\"status_code\":200,\"error\":\"\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:                 "url is canonicalized before fetching and matching",
+			url:                  "HTTPS://%D0%BF%D1%80%D0%B8%D0%BC%D0%B5%D1%80.EXAMPLE.com:443/%61rticles/1",
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "[{\"location\":\"/articles/*\",\"tdm-reservation\":1}]",
+			expectedResponse: "{\"url\":\"https://xn--e1afmkfd.example.com/articles/1\",\"reservation\":true," +
+				"\"source\":\"tdmrep.json\",\"location\":\"/articles/*\",\"status_code\":200,\"error\":\"\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:                 "reservation from http headers",
+			url:                  "https://example.com/articles/1",
+			mockHttpResponseCode: http.StatusNotFound,
+			mockHttpResponseBody: "",
+			mockHttpHeaders:      map[string]string{"tdm-reservation": "1"},
+			expectedResponse: "{\"url\":\"https://example.com/articles/1\",\"reservation\":true," +
+				"\"source\":\"http-header\",\"status_code\":404,\"error\":\"\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "fetch error",
+			url:           "https://example.com/articles/1",
+			mockHttpError: &net.OpError{Op: "remote error", Err: tls.AlertError(40)},
+			expectedResponse: "{\"url\":\"https://example.com/articles/1\",\"reservation\":false,\"source\":\"none\"," +
+				"\"status_code\":500,\"error\":\"the tls handshake with the target failed\",\"error_code\":\"TLS_ERROR\"}",
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:                 "tdmrep.json server error",
+			url:                  "https://example.com/articles/1",
+			mockHttpResponseCode: http.StatusServiceUnavailable,
+			mockHttpResponseBody: "",
+			mockHttpHeaders:      map[string]string{"tdm-reservation": "0"},
+			expectedResponse: "{\"url\":\"https://example.com/articles/1\",\"reservation\":false,\"source\":\"none\"," +
+				"\"status_code\":503,\"error\":\"tdmrep.json is not available\",\"error_code\":\"FETCH_FAILED\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:                 "malformed tdmrep.json",
+			url:                  "https://example.com/articles/1&check_headers=false",
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "{\"location\":",
+			expectedCachedFile:   "{\"status_code\":200,\"error\":\"unexpected end of JSON input\"}",
+			expectedResponse: "{\"url\":\"https://example.com/articles/1\",\"reservation\":false,\"source\":\"none\"," +
+				"\"status_code\":200,\"error\":\"tdmrep.json is invalid\",\"error_code\":\"INVALID_TDMREP\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "cached invalid tdmrep.json is not fetched again",
+			url:            "https://example.com/articles/1",
+			mockHttpError:  errors.New("unexpected request"),
+			mockCachedFile: "{\"status_code\":200,\"error\":\"unexpected end of JSON input\"}",
+			expectedResponse: "{\"url\":\"https://example.com/articles/1\",\"reservation\":false,\"source\":\"none\"," +
+				"\"status_code\":200,\"error\":\"tdmrep.json is invalid\",\"error_code\":\"INVALID_TDMREP\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "cached rules",
+			url:            "https://example.com/articles/1&check_headers=false",
+			mockHttpError:  errors.New("unexpected request"),
+			mockCachedFile: "{\"status_code\":200,\"rules\":[{\"location\":\"/articles/*\",\"tdm-reservation\":1}]}",
+			expectedResponse: "{\"url\":\"https://example.com/articles/1\",\"reservation\":true," +
+				"\"source\":\"tdmrep.json\",\"location\":\"/articles/*\",\"status_code\":200,\"error\":\"\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:                 "missed url in query",
+			url:                  "",
+			mockHttpResponseCode: http.StatusOK,
+			mockHttpResponseBody: "",
+			expectedResponse: "{\"url\":\"\",\"reservation\":false,\"source\":\"none\",\"status_code\":400," +
+				"\"error\":\"'url' query parameter is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cfg := &config.Config{
+				RuleUserAgent: "robots-bot",
+				TelemetrySettings: &config.TelemetryConfig{
+					Enabled: false,
+				},
+			}
+			// mock cache
+			cache := cacheMock.NewCachedClient(tt)
+			// the cache key is the canonical url
+			isCanonicalUrl := func(url string) bool {
+				canonical, err := util.CanonicalizeUrl(url)
+				return err == nil && canonical == url
+			}
+			if test.mockCachedFile != "" {
+				cache.On("GetTdmRepFile", mock.MatchedBy(isCanonicalUrl)).Once().Return([]byte(test.mockCachedFile), true)
+			} else {
+				cache.On("GetTdmRepFile", mock.MatchedBy(isCanonicalUrl)).Maybe().Return(nil, false)
+			}
+			cache.On("SaveTdmRepFile", mock.MatchedBy(isCanonicalUrl), mock.Anything).Maybe()
+			// mock http client
+			httpMock := httptest.NewRecorder()
+			for k, v := range test.mockHttpHeaders {
+				httpMock.Header().Set(k, v)
+			}
+			httpMock.WriteString(test.mockHttpResponseBody)
+			httpMock.Code = test.mockHttpResponseCode
+			httpClient := &http.Client{Transport: &mockRoundTripper{response: httpMock.Result()}}
+			if test.mockHttpError != nil {
+				httpClient.Transport = &errorRoundTripper{err: test.mockHttpError}
+			}
+
+			r := gin.Default()
+			robotsHandler := NewRuleApiHandler(cfg, cache, nil, httpClient, metrics.ApiMetrics)
+			r.GET("/tdm-reservation", robotsHandler.GetTdmReservation)
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/tdm-reservation?url=%s", test.url), nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			responseData, _ := io.ReadAll(w.Body)
+			assert.Equal(tt, test.expectedResponse, string(responseData))
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+			if test.expectedCachedFile != "" {
+				cache.AssertCalled(tt, "SaveTdmRepFile", mock.Anything, []byte(test.expectedCachedFile))
+			}
+		})
+	}
+}
+
+func Test_GetPageDirectives_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := telemetry.SetupMetrics(context.Background(), &config.Config{
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	})
+	testSet := []struct {
+		name                 string
+		url                  string
+		userAgent            string
+		mockHttpResponseBody string
+		mockHttpHeaders      map[string][]string
+		mockHttpError        error
+		expectedResponse     string
+		expectedStatusCode   int
+	}{
+		{
+			name:      "directives from headers and meta tags",
+			url:       "https://example.com/page",
+			userAgent: "GPTBot/1.0",
+			mockHttpResponseBody: "<html><head><meta name=\"robots\" content=\"noindex\">" +
+				"<meta name=\"gptbot\" content=\"noai\"><meta name=\"otherbot\" content=\"none\"></head>" +
+				"<body><meta name=\"robots\" content=\"nofollow\"></body></html>",
+			mockHttpHeaders: map[string][]string{
+				"Content-Type": {"text/html; charset=utf-8"},
+				"X-Robots-Tag": {"otherbot: nofollow", "noimageai"},
+			},
+			expectedResponse: "{\"url\":\"https://example.com/page\",\"user_agent\":\"GPTBot/1.0\"," +
+				"\"directives\":[\"noimageai\",\"noindex\",\"noai\"],\"noindex\":true,\"nofollow\":false,\"noai\":true," +
+				"\"noimageai\":true,\"status_code\":200,\"error\":\"\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:      "meta names apply only to the product token of the user agent",
+			url:       "https://example.com/page",
+			userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			mockHttpResponseBody: "<html><head><meta name=\"google\" content=\"noindex\">" +
+				"<meta name=\"bot\" content=\"none\"><meta name=\"mozilla\" content=\"nofollow\">" +
+				"<meta name=\"compatible\" content=\"noimageai\"><meta name=\"og:title\" content=\"nofollow\">" +
+				"<meta name=\"googlebot\" content=\"noai\"><meta name=\"robots\" content=\"max-snippet:10\">" +
+				"</head><body></body></html>",
+			mockHttpHeaders: map[string][]string{
+				"Content-Type": {"text/html"},
+				"X-Robots-Tag": {"www: noindex"},
+			},
+			expectedResponse: "{\"url\":\"https://example.com/page\"," +
+				"\"user_agent\":\"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)\"," +
+				"\"directives\":[\"noai\",\"max-snippet:10\"],\"noindex\":false,\"nofollow\":false,\"noai\":true," +
+				"\"noimageai\":false,\"status_code\":200,\"error\":\"\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:      "meta name of the caller applies",
+			url:       "https://example.com/page",
+			userAgent: "bot",
+			mockHttpResponseBody: "<html><head><meta name=\"bot\" content=\"none\">" +
+				"<meta name=\"google\" content=\"noai\"></head><body></body></html>",
+			mockHttpHeaders: map[string][]string{
+				"Content-Type": {"text/html"},
+			},
+			expectedResponse: "{\"url\":\"https://example.com/page\",\"user_agent\":\"bot\"," +
+				"\"directives\":[\"none\"],\"noindex\":true,\"nofollow\":true,\"noai\":false," +
+				"\"noimageai\":false,\"status_code\":200,\"error\":\"\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:      "unavailable_after is not a user agent",
+			url:       "https://example.com/page",
+			userAgent: "bot",
+			mockHttpHeaders: map[string][]string{
+				"X-Robots-Tag": {"unavailable_after: 25 Jun 2010 15:00:00 PST"},
+			},
+			expectedResponse: "{\"url\":\"https://example.com/page\",\"user_agent\":\"bot\"," +
+				"\"directives\":[\"unavailable_after: 25 jun 2010 15:00:00 pst\"],\"noindex\":false,\"nofollow\":false," +
+				"\"noai\":false,\"noimageai\":false,\"status_code\":200,\"error\":\"\"}",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "fetch error",
+			url:           "https://example.com/page",
+			userAgent:     "bot",
+			mockHttpError: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			expectedResponse: "{\"url\":\"https://example.com/page\",\"user_agent\":\"bot\",\"directives\":[]," +
+				"\"noindex\":false,\"nofollow\":false,\"noai\":false,\"noimageai\":false,\"status_code\":500," +
+				"\"error\":\"failed to connect to the target\",\"error_code\":\"CONNECTION_FAILED\"}",
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:      "missed user_agent in query",
+			url:       "https://example.com/page",
+			userAgent: "",
+			expectedResponse: "{\"url\":\"https://example.com/page\",\"user_agent\":\"\",\"directives\":[]," +
+				"\"noindex\":false,\"nofollow\":false,\"noai\":false,\"noimageai\":false,\"status_code\":400," +
+				"\"error\":\"'user_agent' query parameter is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cfg := &config.Config{
+				RuleUserAgent: "robots-bot",
+				TelemetrySettings: &config.TelemetryConfig{
+					Enabled: false,
+				},
+			}
+			// mock cache
+			cache := cacheMock.NewCachedClient(tt)
+			cache.On("GetPageDirectives", mock.Anything).Maybe().Return(nil, false)
+			cache.On("SavePageDirectives", mock.Anything, mock.Anything).Maybe()
+			// mock http client
+			httpMock := httptest.NewRecorder()
+			for k, values := range test.mockHttpHeaders {
+				for _, v := range values {
+					httpMock.Header().Add(k, v)
+				}
+			}
+			httpMock.WriteString(test.mockHttpResponseBody)
+			httpClient := &http.Client{Transport: &mockRoundTripper{response: httpMock.Result()}}
+			if test.mockHttpError != nil {
+				httpClient.Transport = &errorRoundTripper{err: test.mockHttpError}
+			}
+
+			r := gin.Default()
+			robotsHandler := NewRuleApiHandler(cfg, cache, nil, httpClient, metrics.ApiMetrics)
+			r.GET("/page-directives", robotsHandler.GetPageDirectives)
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/page-directives?url=%s&user_agent=%s",
+				test.url, u.QueryEscape(test.userAgent)), nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			responseData, _ := io.ReadAll(w.Body)
+			assert.Equal(tt, test.expectedResponse, string(responseData))
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+// errorRoundTripper fails every request with the error.
+type errorRoundTripper struct {
+	err error
+}
+
+func (rt *errorRoundTripper) RoundTrip(_ *http.Request) (*http.Response, error) {
+	return nil, rt.err
+}
+
+type httpOnlyRoundTripper struct {
+	mockRoundTripper
+}
+
+func (rt *httpOnlyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "https" {
+		return nil, tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}
+	}
+	return rt.mockRoundTripper.RoundTrip(req)
+}
+
+func Test_GetAllowedCrawl_SchemelessUrl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := telemetry.SetupMetrics(context.Background(), &config.Config{
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	})
+	cfg := &config.Config{
+		RuleUserAgent: "robots-bot",
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	}
+	cache := cacheMock.NewCachedClient(t)
+	cache.On("GetRobotsFile", mock.Anything).Maybe().Return(nil, false)
+	cache.On("SaveRobotsFile", mock.Anything, mock.Anything).Maybe()
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("GetByUrl", mock.Anything).Maybe().Return(nil, errors.New("not found"))
+	httpMock := httptest.NewRecorder()
+	httpMock.WriteString("User-agent: * \n Disallow: /private")
+	httpClient := &http.Client{Transport: &httpOnlyRoundTripper{mockRoundTripper{response: httpMock.Result()}}}
+
+	r := gin.Default()
+	robotsHandler := NewRuleApiHandler(cfg, cache, ruleRepo, httpClient, metrics.ApiMetrics)
+	robotsHandler.now = func() time.Time { return testTime }
+	r.GET("/crawl-allowed", robotsHandler.GetAllowedCrawl)
+
+	for _, url := range []string{"example.com/test", "//example.com/test"} {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/crawl-allowed?url=%s&user_agent=bot", url), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		responseData, _ := io.ReadAll(w.Body)
+		assert.Equal(t, "{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\","+
+			"\"resolved_origin\":\"http://example.com\",\"registrable_domain\":\"example.com\","+
+			"\"content\":{\"content_type\":\"text/plain; charset=utf-8\",\"charset\":\"utf-8\",\"bom\":false,"+
+			"\"detected\":\"robots.txt\",\"valid\":true},\"source\":\"live\","+
+			"\"robots_url\":\"http://example.com/robots.txt\",\"fetched_at\":\"2025-01-01T12:00:00Z\"}",
+			string(responseData))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
+
+func Test_GetAllowedCrawl_RuleAlias(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := telemetry.SetupMetrics(context.Background(), &config.Config{
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	})
+	cfg := &config.Config{
+		RuleUserAgent: "robots-bot",
+		RuleAliasSettings: &config.RuleAliasConfig{
+			WwwFallback:       true,
+			AliasTable:        true,
+			RegistrableDomain: true,
+		},
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	}
+	rule := &model.Rule{ID: 1, Domain: "example.com", RobotsTxt: "User-agent: * \n Disallow: /private"}
+	cache := cacheMock.NewCachedClient(t)
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("GetByUrl", mock.Anything).Return(nil, persistence.ErrNotFound)
+	ruleRepo.On("GetByDomain", "example.com").Return(rule, nil)
+	ruleRepo.On("GetByDomain", mock.Anything).Maybe().Return(nil, persistence.ErrNotFound)
+	ruleRepo.On("GetAlias", "example.co.uk").Maybe().Return(
+		&model.DomainAlias{ID: 1, Alias: "example.co.uk", Domain: "example.com"}, nil)
+	ruleRepo.On("GetAlias", mock.Anything).Maybe().Return(nil, persistence.ErrNotFound)
+
+	r := gin.Default()
+	robotsHandler := NewRuleApiHandler(cfg, cache, ruleRepo, &http.Client{}, metrics.ApiMetrics)
+	r.GET("/crawl-allowed", robotsHandler.GetAllowedCrawl)
+
+	for url, registrableDomain := range map[string]string{
+		"https://www.example.com/private":       "example.com",
+		"https://example.co.uk/private":         "example.co.uk",
+		"https://blog.news.example.com/private": "example.com",
+	} {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/crawl-allowed?url=%s&user_agent=bot", url), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		responseData, _ := io.ReadAll(w.Body)
+		assert.Equal(t, "{\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\","+
+			"\"matched_alias\":\"example.com\",\"registrable_domain\":\""+registrableDomain+"\","+
+			"\"source\":\"custom_rule\",\"rule_id\":1}",
+			string(responseData))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
+
+func Test_GetAllowedCrawl_ForbiddenAddress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := telemetry.SetupMetrics(context.Background(), &config.Config{
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	})
+	cfg := &config.Config{
+		RuleUserAgent: "robots-bot",
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	}
+	cache := cacheMock.NewCachedClient(t)
+	cache.On("GetRobotsFile", mock.Anything).Return(nil, false)
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("GetByUrl", mock.Anything).Return(nil, errors.New("not found"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("User-agent: *\nAllow: /"))
+	}))
+	defer server.Close()
+	guard, _ := ssrf.NewGuard(nil, nil, nil)
+	httpClient := &http.Client{Transport: &http.Transport{DialContext: guard.DialContext(&net.Dialer{}, nil)}}
+
+	r := gin.Default()
+	robotsHandler := NewRuleApiHandler(cfg, cache, ruleRepo, httpClient, metrics.ApiMetrics)
+	r.GET("/crawl-allowed", robotsHandler.GetAllowedCrawl)
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/crawl-allowed?url=%s/test&user_agent=bot", server.URL), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	responseData, _ := io.ReadAll(w.Body)
+	assert.Contains(t, string(responseData), "\"is_allowed\":false,\"blocked\":false,\"status_code\":403")
+	assert.Contains(t, string(responseData), "\"error\":\"the target address is not allowed\","+
+		"\"error_code\":\"FORBIDDEN_ADDRESS\"")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func Test_StreamAllowedCrawl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics := telemetry.SetupMetrics(context.Background(), &config.Config{
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	})
+	cfg := &config.Config{
+		RuleUserAgent: "robots-bot",
+		CrawlStreamSettings: &config.CrawlStreamConfig{
+			Concurrency:   2,
+			MaxLineSizeKb: 1,
+		},
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	}
+	ruleRepo := storageMock.NewRuleStorage(t)
+	ruleRepo.On("GetByUrl", mock.Anything).Return(
+		&model.Rule{ID: 1, Domain: "example.com", RobotsTxt: "User-agent: *\nDisallow: /private"}, nil)
+	robotsHandler := NewRuleApiHandler(cfg, cacheMock.NewCachedClient(t), ruleRepo, &http.Client{}, metrics.ApiMetrics)
+	r := gin.Default()
+	r.POST("/crawl-allowed/stream", func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 4096)
+	}, robotsHandler.StreamAllowedCrawl)
+
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{
+			name: "records are checked",
+			body: "{\"url\":\"https://example.com/public\",\"user_agent\":\"bot\"}\n\n" +
+				"{\"url\":\"https://example.com/private\",\"user_agent\":\"bot\"}\n" +
+				"not json\n" +
+				"{\"url\":\"\",\"user_agent\":\"bot\"}",
+			expected: []string{
+				"{\"index\":0,\"url\":\"https://example.com/public\",\"user_agent\":\"bot\",\"is_allowed\":true," +
+					"\"blocked\":false,\"status_code\":200,\"error\":\"\",\"registrable_domain\":\"example.com\"," +
+					"\"source\":\"custom_rule\",\"rule_id\":1}",
+				"{\"index\":1,\"url\":\"https://example.com/private\",\"user_agent\":\"bot\",\"is_allowed\":false," +
+					"\"blocked\":false,\"status_code\":200,\"error\":\"\",\"registrable_domain\":\"example.com\"," +
+					"\"source\":\"custom_rule\",\"rule_id\":1}",
+				"{\"index\":2,\"url\":\"\",\"user_agent\":\"\",\"is_allowed\":false,\"blocked\":false," +
+					"\"status_code\":400,\"error\":\"invalid json record. invalid character 'o' in literal null " +
+					"(expecting 'u')\",\"error_code\":\"INVALID_REQUEST\"}",
+				"{\"index\":3,\"url\":\"\",\"user_agent\":\"bot\",\"is_allowed\":false,\"blocked\":false," +
+					"\"status_code\":400,\"error\":\"'url' query parameter is required\",\"error_code\":\"INVALID_URL\"}",
+			},
+		},
+		{
+			name: "too long record",
+			body: "{\"url\":\"https://example.com/" + strings.Repeat("a", 1024) + "\",\"user_agent\":\"bot\"}\n",
+			expected: []string{
+				"{\"index\":0,\"url\":\"\",\"user_agent\":\"\",\"is_allowed\":false,\"blocked\":false," +
+					"\"status_code\":400,\"error\":\"the record exceeds the limit of 1024 bytes\"," +
+					"\"error_code\":\"INVALID_REQUEST\"}",
+			},
+		},
+		{
+			name: "body exceeds the limit",
+			body: strings.Repeat("{\"url\":\"https://example.com/public\",\"user_agent\":\"bot\"}\n", 80),
+			expected: []string{
+				"{\"index\":73,\"url\":\"\",\"user_agent\":\"\",\"is_allowed\":false,\"blocked\":false," +
+					"\"status_code\":400,\"error\":\"the request body exceeds the limit of 4096 bytes\"," +
+					"\"error_code\":\"BODY_TOO_LARGE\"}",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			req, _ := http.NewRequest("POST", "/crawl-allowed/stream", strings.NewReader(test.body))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(tt, http.StatusOK, w.Code)
+			assert.Equal(tt, "application/x-ndjson", w.Header().Get("Content-Type"))
+			lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+			// the results are unordered
+			sort.Slice(lines, func(i, j int) bool {
+				var a, b model.CrawlStreamResult
+				_ = json.Unmarshal([]byte(lines[i]), &a)
+				_ = json.Unmarshal([]byte(lines[j]), &b)
+				return a.Index < b.Index
+			})
+			if test.name == "body exceeds the limit" {
+				// the 73 records before the limit are checked
+				assert.Len(tt, lines, 74)
+				lines = lines[73:]
+			}
+			assert.Equal(tt, test.expected, lines)
+		})
+	}
+}
+
+func Test_AbortWithError_RequestId(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestId())
+	r.GET("/error", func(c *gin.Context) {
+		AbortWithError(c, http.StatusConflict, "failed to save custom rule", persistence.ErrDuplicate)
+	})
+
+	req, _ := http.NewRequest("GET", "/error", nil)
+	req.Header.Set(RequestIdHeader, "abc-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "{\"code\":\"CONFLICT\",\"message\":\"failed to save custom rule\",\"details\":\"already exists\","+
+		"\"request_id\":\"abc-123\"}", w.Body.String())
+	assert.Equal(t, "abc-123", w.Header().Get(RequestIdHeader))
+
+	// the id is generated if the header is missing
+	req, _ = http.NewRequest("GET", "/error", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Len(t, w.Header().Get(RequestIdHeader), 32)
+	assert.Contains(t, w.Body.String(), w.Header().Get(RequestIdHeader))
+}
+
+func utf16le(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		sb.WriteByte(byte(r))
+		sb.WriteByte(byte(r >> 8))
+	}
+	return sb.String()
+}
+
+func brotliString(s string) string {
+	var buf bytes.Buffer
+	w := brotli.NewWriter(&buf)
+	_, _ = w.Write([]byte(s))
+	_ = w.Close()
+	return buf.String()
+}
+
+func Test_CheckCrawlGroup(t *testing.T) {
+	metrics := telemetry.SetupMetrics(context.Background(), &config.Config{
+		TelemetrySettings: &config.TelemetryConfig{
+			Enabled: false,
+		},
+	})
+	rule := &model.Rule{ID: 1, Domain: "example.com", RobotsTxt: "User-agent: * \n Disallow: /private"}
+	ruleRepo := storageMock.NewRuleStorage(t)
+	// the custom rule is resolved once for the group
+	ruleRepo.On("GetByUrl", "https://example.com/private").Once().Return(rule, nil)
+	robotsHandler := NewRuleApiHandler(&config.Config{}, cacheMock.NewCachedClient(t), ruleRepo, &http.Client{},
+		metrics.ApiMetrics)
+
+	responses := robotsHandler.CheckCrawlGroup(context.Background(),
+		[]string{"https://example.com/private", "https://example.com/public", ""}, "bot", "")
+
+	responseData, _ := json.Marshal(responses)
+	assert.Equal(t, "[{\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\","+
+		"\"registrable_domain\":\"example.com\",\"source\":\"custom_rule\",\"rule_id\":1},"+
+		"{\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\","+
+		"\"registrable_domain\":\"example.com\",\"source\":\"custom_rule\",\"rule_id\":1},"+
+		"{\"is_allowed\":false,\"blocked\":false,\"status_code\":400,\"error\":\"'url' is required\","+
+		"\"error_code\":\"INVALID_URL\"}]", string(responseData))
+}
+
+func Test_CreateJob_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testSet := []struct {
+		name               string
+		query              string
+		contentType        string
+		body               string
+		expectedList       string
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name:               "url list in request body",
+			query:              "user_agent=bot&purpose=search-indexing",
+			contentType:        "text/plain",
+			body:               "https://example.com/a\n\n  example.com/b \r\nhttps://other.com/c",
+			expectedList:       "https://example.com/a\nexample.com/b\nhttps://other.com/c\n",
+			expectedStatusCode: http.StatusAccepted,
+		},
+		{
+			name:               "url list in multipart form",
+			query:              "user_agent=bot",
+			contentType:        "multipart/form-data",
+			body:               "https://example.com/a\nhttps://example.com/b\n",
+			expectedList:       "https://example.com/a\nhttps://example.com/b\n",
+			expectedStatusCode: http.StatusAccepted,
+		},
+		{
+			name:               "empty user agent",
+			query:              "",
+			contentType:        "text/plain",
+			body:               "https://example.com/a",
+			expectedResponse:   "{\"code\":\"BAD_REQUEST\",\"message\":\"'user_agent' query parameter is required\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:        "too long user agent",
+			query:       "user_agent=" + strings.Repeat("a", 256),
+			contentType: "text/plain",
+			body:        "https://example.com/a",
+			expectedResponse: "{\"code\":\"BAD_REQUEST\"," +
+				"\"message\":\"'user_agent' must not be longer than 255 characters\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:        "too long purpose",
+			query:       "user_agent=bot&purpose=" + strings.Repeat("a", 51),
+			contentType: "text/plain",
+			body:        "https://example.com/a",
+			expectedResponse: "{\"code\":\"BAD_REQUEST\"," +
+				"\"message\":\"'purpose' must not be longer than 50 characters\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:        "unsupported purpose",
+			query:       "user_agent=bot&purpose=unknown",
+			contentType: "text/plain",
+			body:        "https://example.com/a",
+			expectedResponse: "{\"code\":\"BAD_REQUEST\"," +
+				"\"message\":\"unsupported 'purpose' query parameter 'unknown'\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "empty url list",
+			query:              "user_agent=bot",
+			contentType:        "text/plain",
+			body:               "\n \n",
+			expectedResponse:   "{\"code\":\"BAD_REQUEST\",\"message\":\"the url list is empty\"}",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:        "url list exceeds the limit",
+			query:       "user_agent=bot",
+			contentType: "text/plain",
+			body:        strings.Repeat("https://example.com/a\n", 50000),
+			expectedResponse: "{\"code\":\"PAYLOAD_TOO_LARGE\",\"message\":\"failed to save the url list\"," +
+				"\"details\":\"http: request body too large\"}",
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			cfg := &config.Config{
+				RuleApiUrlPath: "/rule/v1",
+				CrawlPurposes:  map[string][]string{"search-indexing": {"Googlebot"}},
+				JobSettings:    &config.JobConfig{MaxUploadSizeMb: 1},
+			}
+			jobRepo := storageMock.NewJobStorage(tt)
+			// the url list is saved with the job
+			var list strings.Builder
+			jobRepo.On("CreateJob", mock.Anything, mock.Anything).Maybe().Return(func(job *model.Job,
+				urls iter.Seq2[string, error]) error {
+				for url, err := range urls {
+					if err != nil {
+						return err
+					}
+					list.WriteString(url + "\n")
+					job.Total++
+				}
+				return nil
+			})
+
+			body, contentType := strings.NewReader(test.body), test.contentType
+			if test.contentType == "multipart/form-data" {
+				var form bytes.Buffer
+				writer := multipart.NewWriter(&form)
+				part, _ := writer.CreateFormFile("file", "urls.txt")
+				_, _ = part.Write([]byte(test.body))
+				_ = writer.Close()
+				body, contentType = strings.NewReader(form.String()), writer.FormDataContentType()
+			}
+			r := gin.Default()
+			r.POST("/jobs", NewJobHandler(cfg, jobRepo).CreateJob)
+			req, _ := http.NewRequest("POST", "/jobs?"+test.query, body)
+			req.Header.Set("Content-Type", contentType)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			responseData, _ := io.ReadAll(w.Body)
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+			if test.expectedStatusCode != http.StatusAccepted {
+				assert.Equal(tt, test.expectedResponse, string(responseData))
+				return
+			}
+			var job model.Job
+			assert.NoError(tt, json.Unmarshal(responseData, &job))
+			assert.Equal(tt, strings.Count(test.expectedList, "\n"), job.Total)
+			assert.Equal(tt, "/rule/v1/jobs/"+job.ID, w.Header().Get("Location"))
+			assert.Equal(tt, test.expectedList, list.String())
+		})
+	}
+}
+
+func Test_GetJobResult_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	result := "{\"index\":0,\"url\":\"https://example.com/a\",\"user_agent\":\"bot\",\"is_allowed\":true," +
+		"\"blocked\":false,\"status_code\":200,\"error\":\"\"}\n"
+	testSet := []struct {
+		name               string
+		id                 string
+		mockStorage        func() (*model.Job, error)
+		expectedResponse   string
+		expectedStatusCode int
+	}{
+		{
+			name: "completed job",
+			id:   "completed",
+			mockStorage: func() (*model.Job, error) {
+				return &model.Job{ID: "completed", Status: model.JobStatusCompleted}, nil
+			},
+			expectedResponse:   result,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "running job",
+			id:   "running",
+			mockStorage: func() (*model.Job, error) {
+				return &model.Job{ID: "running", Status: model.JobStatusRunning}, nil
+			},
+			expectedResponse:   "{\"code\":\"CONFLICT\",\"message\":\"the job is running\"}",
+			expectedStatusCode: http.StatusConflict,
+		},
+		{
+			name: "non-existent job",
+			id:   "missing",
+			mockStorage: func() (*model.Job, error) {
+				return nil, fmt.Errorf("job with id 'missing' %w", persistence.ErrNotFound)
+			},
+			expectedResponse: "{\"code\":\"NOT_FOUND\",\"message\":\"failed to get job\"," +
+				"\"details\":\"job with id 'missing' not found\"}",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			jobRepo := storageMock.NewJobStorage(tt)
+			jobRepo.On("GetJob", test.id).Return(test.mockStorage())
+			// the result is read from the storage by any instance
+			jobRepo.On("WriteJobResult", "completed", mock.Anything).Maybe().Return(func(_ string,
+				w io.Writer) error {
+				_, err := io.WriteString(w, result)
+				return err
+			})
+
+			r := gin.Default()
+			cfg := &config.Config{JobSettings: &config.JobConfig{}}
+			r.GET("/jobs/:id/result", NewJobHandler(cfg, jobRepo).GetJobResult)
+			req, _ := http.NewRequest("GET", fmt.Sprintf("/jobs/%s/result", test.id), nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			responseData, _ := io.ReadAll(w.Body)
+			assert.Equal(tt, test.expectedResponse, string(responseData))
+			assert.Equal(tt, test.expectedStatusCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	u "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/IliaW/rule-api/engine"
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/util"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errTdmRepUnavailable = errors.New("tdmrep.json returned an unexpected status")
+	errInvalidTdmRep     = errors.New("invalid tdmrep.json")
+)
+
+const (
+	tdmRepPath = "/.well-known/tdmrep.json"
+
+	tdmSourceWellKnown = "tdmrep.json"
+	tdmSourceHeader    = "http-header"
+	tdmSourceNone      = "none"
+)
+
+// GetTdmReservation godoc
+// @Summary Get the Text and Data Mining reservation for a URL
+// @Description Resolve the TDMRep policy for the URL using the /.well-known/tdmrep.json file and the tdm-reservation headers
+// @Tags Crawling
+// @Produce json
+// @Param url query string true "URL to check"
+// @Param check_headers query bool false "Request the URL headers if tdmrep.json has no matching rule. Default is true"
+// @Success 200 {object} model.TdmReservationResponse "Response object"
+// @Failure 400 {object} model.TdmReservationResponse
+// @Failure 403 {object} model.TdmReservationResponse
+// @Failure 500 {object} model.TdmReservationResponse "tdmrep.json or the page can not be fetched"
+// @Router /tdm-reservation [get]
+func (h *RuleApiHandler) GetTdmReservation(c *gin.Context) {
+	url := c.Query("url")
+	if url == "" {
+		c.JSON(http.StatusBadRequest, model.TdmReservationResponse{
+			Source:     tdmSourceNone,
+			StatusCode: http.StatusBadRequest,
+			Error:      "'url' query parameter is required",
+		})
+		return
+	}
+	checkHeaders, err := strconv.ParseBool(c.DefaultQuery("check_headers", "true"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.TdmReservationResponse{
+			Url:        url,
+			Source:     tdmSourceNone,
+			StatusCode: http.StatusBadRequest,
+			Error:      "unable to parse 'check_headers' query parameter",
+		})
+		return
+	}
+	parsedUrl, err := u.Parse(url)
+	if err != nil || parsedUrl.Hostname() == "" {
+		c.JSON(http.StatusBadRequest, model.TdmReservationResponse{
+			Url:        url,
+			Source:     tdmSourceNone,
+			StatusCode: http.StatusBadRequest,
+			Error:      "invalid url. Url should contain scheme and hostname",
+		})
+		return
+	}
+	if canonicalUrl, err := util.CanonicalizeUrl(url); err == nil {
+		url = canonicalUrl
+		parsedUrl, _ = u.Parse(url)
+	}
+
+	// the tdmrep.json file has priority over the http headers
+	file, err := h.getTdmRepFile(c.Request.Context(), url)
+	if err != nil {
+		// the reservation is unknown, it is not reported as missing
+		errorCode, message := tdmRepErrorCode(err)
+		slog.Warn("failed to get tdmrep.json.", slog.String("url", url), slog.String("error_code", errorCode),
+			slog.String("err", err.Error()))
+		statusCode := engine.FetchErrorStatus(err)
+		respStatusCode := statusCode
+		if file != nil {
+			// the target served an unusable file, it is not a failure of the service
+			statusCode = http.StatusOK
+			respStatusCode = file.StatusCode
+		}
+		c.JSON(statusCode, model.TdmReservationResponse{
+			Url:        url,
+			Source:     tdmSourceNone,
+			StatusCode: respStatusCode,
+			Error:      message,
+			ErrorCode:  errorCode,
+		})
+		return
+	}
+	for _, rule := range file.Rules {
+		if matchTdmLocation(rule.Location, parsedUrl.RequestURI()) {
+			c.JSON(http.StatusOK, model.TdmReservationResponse{
+				Url:         url,
+				Reservation: rule.Reservation == 1,
+				Policy:      rule.Policy,
+				Source:      tdmSourceWellKnown,
+				Location:    rule.Location,
+				StatusCode:  http.StatusOK,
+			})
+			return
+		}
+	}
+
+	if !checkHeaders {
+		c.JSON(http.StatusOK, model.TdmReservationResponse{
+			Url:        url,
+			Source:     tdmSourceNone,
+			StatusCode: http.StatusOK,
+		})
+		return
+	}
+
+	tResp, err := h.fetcher.Fetch(c.Request.Context(), http.MethodHead, url, 0)
+	if err != nil {
+		errorCode, message := engine.ClassifyFetchError(err, "page")
+		slog.Warn("failed to get the tdm headers.", slog.String("url", url), slog.String("error_code", errorCode),
+			slog.String("err", err.Error()))
+		statusCode := engine.FetchErrorStatus(err)
+		c.JSON(statusCode, model.TdmReservationResponse{
+			Url:        url,
+			Source:     tdmSourceNone,
+			StatusCode: statusCode,
+			Error:      message,
+			ErrorCode:  errorCode,
+		})
+		return
+	}
+	reservation := strings.TrimSpace(tResp.Header.Get("tdm-reservation"))
+	if reservation == "" {
+		c.JSON(http.StatusOK, model.TdmReservationResponse{
+			Url:        url,
+			Source:     tdmSourceNone,
+			StatusCode: tResp.StatusCode,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TdmReservationResponse{
+		Url:         url,
+		Reservation: reservation == "1",
+		Policy:      strings.TrimSpace(tResp.Header.Get("tdm-policy")),
+		Source:      tdmSourceHeader,
+		StatusCode:  tResp.StatusCode,
+	})
+}
+
+// getTdmRepFile gets the tdmrep.json file for the origin of the url from the cache or the target. The file is
+// returned with an error if the target responded, but the file can not be used: the status is unexpected or
+// the file is invalid.
+func (h *RuleApiHandler) getTdmRepFile(ctx context.Context, url string) (*model.TdmRepFile, error) {
+	// check if the tdmrep.json file is already saved in cache
+	if data, ok := h.cache.GetTdmRepFile(url); ok {
+		var file model.TdmRepFile
+		if err := json.Unmarshal(data, &file); err == nil {
+			return &file, tdmRepFileError(&file)
+		}
+	}
+
+	tResp, err := engine.FetchFile(ctx, h.fetcher, url, tdmRepPath)
+	if err != nil {
+		return nil, err
+	}
+	file := &model.TdmRepFile{StatusCode: tResp.StatusCode}
+	switch {
+	case isSuccess(tResp.StatusCode) && len(tResp.Body) != 0:
+		// the invalid file is cached as well. There is no reason to fetch it again before the ttl expires
+		if err = json.Unmarshal(tResp.Body, &file.Rules); err != nil {
+			file.Rules = nil
+			file.Error = err.Error()
+		}
+	case tResp.StatusCode >= 400 && tResp.StatusCode < 500:
+		// the missing file has no rules
+	default:
+		return file, fmt.Errorf("%w. Status code %d", errTdmRepUnavailable, tResp.StatusCode)
+	}
+	if data, err := json.Marshal(file); err != nil {
+		slog.Error("failed to encode tdmrep.json file.", slog.String("err", err.Error()))
+	} else {
+		h.cache.SaveTdmRepFile(url, data)
+	}
+
+	return file, tdmRepFileError(file)
+}
+
+func tdmRepFileError(file *model.TdmRepFile) error {
+	if file.Error != "" {
+		return fmt.Errorf("%w. %s", errInvalidTdmRep, file.Error)
+	}
+	return nil
+}
+
+// tdmRepErrorCode returns the error code and a short message for the error of getTdmRepRules.
+func tdmRepErrorCode(err error) (string, string) {
+	switch {
+	case errors.Is(err, errTdmRepUnavailable):
+		return model.ErrorCodeFetchFailed, "tdmrep.json is not available"
+	case errors.Is(err, errInvalidTdmRep):
+		return model.ErrorCodeInvalidTdmRep, "tdmrep.json is invalid"
+	default:
+		return engine.ClassifyFetchError(err, "tdmrep.json file")
+	}
+}
+
+// matchTdmLocation matches the path against the tdmrep.json location. Locations use the robots.txt pattern
+// syntax: '*' matches any sequence of characters and '$' at the end anchors the pattern to the end of the path.
+func matchTdmLocation(location string, path string) bool {
+	if location == "" {
+		return false
+	}
+	anchored := strings.HasSuffix(location, "$")
+	location = strings.TrimSuffix(location, "$")
+	if !strings.HasPrefix(location, "/") && !strings.HasPrefix(location, "*") {
+		location = "/" + location
+	}
+	pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(location), `\*`, ".*")
+	if anchored {
+		pattern += "$"
+	}
+	matched, err := regexp.MatchString(pattern, path)
+	if err != nil {
+		return false
+	}
+
+	return matched
+}
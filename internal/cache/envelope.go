@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/IliaW/rule-api/internal/model"
+)
+
+// The robots.txt cache entry is stored as:
+//
+//	magic "RAPI" | version (1 byte) | flags (1 byte) | length of the metadata (uint32) | metadata json | body
+//
+// The body is stored as is, or gzip compressed if the flagCompressed is set. Entries with another magic or version
+// are not decoded, so the instances of different versions can share the cache during rolling upgrades.
+const (
+	envelopeVersion byte = 1
+	flagCompressed  byte = 1 << 0
+	// compressMinSize is the minimal size of the body to compress. Smaller bodies do not get shorter
+	compressMinSize = 512
+)
+
+var (
+	envelopeMagic = []byte("RAPI")
+
+	errUnknownVersion = errors.New("unknown cache entry version")
+)
+
+// robotsMetadata is the metadata of the robots.txt cache entry
+type robotsMetadata struct {
+	StatusCode   int                  `json:"status_code"`
+	Url          string               `json:"url"`
+	Content      *model.RobotsContent `json:"content,omitempty"`
+	FetchedAt    time.Time            `json:"fetched_at"`
+	ExpiresAt    time.Time            `json:"expires_at"`
+	ETag         string               `json:"etag,omitempty"`
+	LastModified string               `json:"last_modified,omitempty"`
+	TtlSeconds   int64                `json:"ttl_seconds"`
+}
+
+// encodeRobotsFile encodes the robots.txt file to the cache entry. The body is compressed if compress is true and the
+// body is not smaller than compressMinSize.
+func encodeRobotsFile(file *model.RobotsFile, ttl time.Duration, compress bool) ([]byte, error) {
+	metadata, err := json.Marshal(robotsMetadata{
+		StatusCode:   file.StatusCode,
+		Url:          file.Url,
+		Content:      file.Content,
+		FetchedAt:    file.FetchedAt,
+		ExpiresAt:    file.ExpiresAt,
+		ETag:         file.ETag,
+		LastModified: file.LastModified,
+		TtlSeconds:   int64(ttl.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var flags byte
+	body := file.Body
+	if compress && len(body) >= compressMinSize {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err = writer.Write(body); err != nil {
+			return nil, err
+		}
+		if err = writer.Close(); err != nil {
+			return nil, err
+		}
+		flags |= flagCompressed
+		body = buf.Bytes()
+	}
+
+	entry := make([]byte, 0, len(envelopeMagic)+6+len(metadata)+len(body))
+	entry = append(entry, envelopeMagic...)
+	entry = append(entry, envelopeVersion, flags)
+	entry = binary.BigEndian.AppendUint32(entry, uint32(len(metadata)))
+	entry = append(entry, metadata...)
+	entry = append(entry, body...)
+
+	return entry, nil
+}
+
+// decodeRobotsFile decodes the cache entry created by encodeRobotsFile. Returns errUnknownVersion if the entry is
+// created by another version of the format.
+func decodeRobotsFile(entry []byte) (*model.RobotsFile, error) {
+	headerSize := len(envelopeMagic) + 6
+	if len(entry) < headerSize || !bytes.HasPrefix(entry, envelopeMagic) ||
+		entry[len(envelopeMagic)] != envelopeVersion {
+		return nil, errUnknownVersion
+	}
+	flags := entry[len(envelopeMagic)+1]
+	metadataSize := int(binary.BigEndian.Uint32(entry[len(envelopeMagic)+2:]))
+	if metadataSize > len(entry)-headerSize {
+		return nil, fmt.Errorf("metadata size %d exceeds the entry", metadataSize)
+	}
+
+	var metadata robotsMetadata
+	if err := json.Unmarshal(entry[headerSize:headerSize+metadataSize], &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata. %s", err.Error())
+	}
+	body := entry[headerSize+metadataSize:]
+	if flags&flagCompressed != 0 {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress body. %s", err.Error())
+		}
+		if body, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("failed to decompress body. %s", err.Error())
+		}
+	}
+
+	return &model.RobotsFile{
+		StatusCode:   metadata.StatusCode,
+		Url:          metadata.Url,
+		Body:         body,
+		Content:      metadata.Content,
+		FetchedAt:    metadata.FetchedAt,
+		ExpiresAt:    metadata.ExpiresAt,
+		ETag:         metadata.ETag,
+		LastModified: metadata.LastModified,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RobotsFileEnvelope(t *testing.T) {
+	fetchedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		body       string
+		compress   bool
+		compressed bool
+	}{
+		{
+			name: "plain body",
+			body: "User-agent: *\nDisallow: /private\n",
+		},
+		{
+			name:     "small body is not compressed",
+			body:     "User-agent: *\nDisallow: /private\n",
+			compress: true,
+		},
+		{
+			name:       "large body is compressed",
+			body:       strings.Repeat("User-agent: *\nDisallow: /private\n", 100),
+			compress:   true,
+			compressed: true,
+		},
+		{
+			name: "binary body",
+			body: "\x00\xff\xfe\"{}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			file := &model.RobotsFile{
+				StatusCode: 200,
+				Url:        "https://example.com/robots.txt",
+				Body:       []byte(test.body),
+				Content: &model.RobotsContent{ContentType: "text/plain", Charset: "utf-8", Detected: "robots.txt",
+					Valid: true},
+				FetchedAt:    fetchedAt,
+				ExpiresAt:    fetchedAt.Add(24 * time.Hour),
+				ETag:         "\"abc\"",
+				LastModified: "Wed, 01 Jan 2025 10:00:00 GMT",
+			}
+			entry, err := encodeRobotsFile(file, 24*time.Hour, test.compress)
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.compressed, entry[len(envelopeMagic)+1]&flagCompressed != 0)
+			if test.compressed {
+				assert.Less(tt, len(entry), len(test.body))
+			}
+
+			decoded, err := decodeRobotsFile(entry)
+			assert.NoError(tt, err)
+			assert.Equal(tt, file, decoded)
+		})
+	}
+}
+
+func Test_RobotsFileEnvelope_UnknownVersion(t *testing.T) {
+	entry, err := encodeRobotsFile(&model.RobotsFile{StatusCode: 200, Body: []byte("User-agent: *")}, 0, false)
+	assert.NoError(t, err)
+	entry[len(envelopeMagic)] = envelopeVersion + 1
+	_, err = decodeRobotsFile(entry)
+	assert.ErrorIs(t, err, errUnknownVersion)
+
+	// entries of the previous format are json encoded
+	legacy, _ := json.Marshal([]byte("User-agent: *"))
+	_, err = decodeRobotsFile(legacy)
+	assert.ErrorIs(t, err, errUnknownVersion)
+
+	_, err = decodeRobotsFile(nil)
+	assert.ErrorIs(t, err, errUnknownVersion)
+
+	// the truncated entry is not an unknown version, but still fails
+	entry, _ = encodeRobotsFile(&model.RobotsFile{StatusCode: 200}, 0, false)
+	_, err = decodeRobotsFile(entry[:len(entry)-2])
+	assert.Error(t, err)
+}
@@ -10,17 +10,31 @@ import (
 	"os"
 
 	"github.com/IliaW/rule-api/config"
+	"github.com/IliaW/rule-api/internal/model"
 	"github.com/IliaW/rule-api/util"
 	"github.com/bradfitz/gomemcache/memcache"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.53.0 --name CachedClient
 type CachedClient interface {
-	GetRobotsFile(string) ([]byte, bool)
-	SaveRobotsFile(string, []byte)
+	GetRobotsFile(string) (*model.RobotsFile, bool)
+	SaveRobotsFile(string, *model.RobotsFile)
+	GetAiTxtFile(string) ([]byte, bool)
+	SaveAiTxtFile(string, []byte)
+	GetTdmRepFile(string) ([]byte, bool)
+	SaveTdmRepFile(string, []byte)
+	GetPageDirectives(string) ([]byte, bool)
+	SavePageDirectives(string, []byte)
 	Close()
 }
 
+const (
+	robotsTxtKeySuffix = "robots-txt"
+	aiTxtKeySuffix     = "ai-txt"
+	tdmRepKeySuffix    = "tdmrep-json"
+	pageDirectivesKey  = "page-directives"
+)
+
 type MemcachedClient struct {
 	client *memcache.Client
 	cfg    *config.CacheConfig
@@ -49,26 +63,36 @@ func NewMemcachedClient(cacheConfig *config.CacheConfig) *MemcachedClient {
 	return c
 }
 
-func (mc *MemcachedClient) GetRobotsFile(url string) ([]byte, bool) {
-	key := mc.generateDomainHash(url)
-	item, err := mc.client.Get(key)
+// GetRobotsFile returns the cached robots.txt file with the fetch metadata. Entries of an unknown format version are
+// treated as a cache miss.
+func (mc *MemcachedClient) GetRobotsFile(url string) (*model.RobotsFile, bool) {
+	key := generateOriginHash(url, robotsTxtKeySuffix)
+	entry, ok := mc.getItem(key, url)
+	if !ok {
+		return nil, false
+	}
+	file, err := decodeRobotsFile(entry)
 	if err != nil {
-		if errors.Is(err, memcache.ErrCacheMiss) {
-			slog.Debug("cache not found.", slog.String("key", key), slog.String("url", url))
-			return nil, false
+		if errors.Is(err, errUnknownVersion) {
+			slog.Debug("unknown version of the cached robots file. Treated as a cache miss.",
+				slog.String("key", key))
 		} else {
-			slog.Error("failed to check if cached.", slog.String("key", key), slog.String("url", url),
+			slog.Error("failed to decode cached robots file.", slog.String("key", key),
 				slog.String("err", err.Error()))
-			return nil, false
 		}
+		return nil, false
 	}
-	slog.Debug("cache found.", slog.String("key", key))
 
-	return item.Value, true
+	return file, true
 }
-func (mc *MemcachedClient) SaveRobotsFile(url string, robotFile []byte) {
-	key := mc.generateDomainHash(url)
-	if err := mc.set(key, robotFile, int32((mc.cfg.TtlForRobotsTxt).Seconds())); err != nil {
+
+func (mc *MemcachedClient) SaveRobotsFile(url string, robotFile *model.RobotsFile) {
+	key := generateOriginHash(url, robotsTxtKeySuffix)
+	entry, err := encodeRobotsFile(robotFile, mc.cfg.TtlForRobotsTxt, mc.cfg.CompressRobotsTxt)
+	if err == nil {
+		err = mc.setItem(key, entry, int32((mc.cfg.TtlForRobotsTxt).Seconds()))
+	}
+	if err != nil {
 		slog.Error("failed to save robots file to cache.", slog.String("key", key),
 			slog.String("err", err.Error()))
 		return
@@ -76,6 +100,49 @@ func (mc *MemcachedClient) SaveRobotsFile(url string, robotFile []byte) {
 	slog.Debug("robots file saved to cache.")
 }
 
+func (mc *MemcachedClient) GetAiTxtFile(url string) ([]byte, bool) {
+	return mc.getFile(generateOriginHash(url, aiTxtKeySuffix), url)
+}
+
+func (mc *MemcachedClient) SaveAiTxtFile(url string, aiFile []byte) {
+	key := generateOriginHash(url, aiTxtKeySuffix)
+	if err := mc.set(key, aiFile, int32((mc.cfg.TtlForAiTxt).Seconds())); err != nil {
+		slog.Error("failed to save ai.txt file to cache.", slog.String("key", key),
+			slog.String("err", err.Error()))
+		return
+	}
+	slog.Debug("ai.txt file saved to cache.")
+}
+
+func (mc *MemcachedClient) GetTdmRepFile(url string) ([]byte, bool) {
+	return mc.getFile(generateOriginHash(url, tdmRepKeySuffix), url)
+}
+
+func (mc *MemcachedClient) SaveTdmRepFile(url string, tdmRepFile []byte) {
+	key := generateOriginHash(url, tdmRepKeySuffix)
+	if err := mc.set(key, tdmRepFile, int32((mc.cfg.TtlForTdmRep).Seconds())); err != nil {
+		slog.Error("failed to save tdmrep.json file to cache.", slog.String("key", key),
+			slog.String("err", err.Error()))
+		return
+	}
+	slog.Debug("tdmrep.json file saved to cache.")
+}
+
+// GetPageDirectives returns the cached robots directives of the page. The key is the full url, not the domain.
+func (mc *MemcachedClient) GetPageDirectives(url string) ([]byte, bool) {
+	return mc.getFile(fmt.Sprintf("%s-%s", hashURL(url), pageDirectivesKey), url)
+}
+
+func (mc *MemcachedClient) SavePageDirectives(url string, directives []byte) {
+	key := fmt.Sprintf("%s-%s", hashURL(url), pageDirectivesKey)
+	if err := mc.set(key, directives, int32((mc.cfg.TtlForPageDirectives).Seconds())); err != nil {
+		slog.Error("failed to save page directives to cache.", slog.String("key", key),
+			slog.String("err", err.Error()))
+		return
+	}
+	slog.Debug("page directives saved to cache.")
+}
+
 func (mc *MemcachedClient) Close() {
 	slog.Info("closing memcached connection.")
 	err := mc.client.Close()
@@ -84,29 +151,76 @@ func (mc *MemcachedClient) Close() {
 	}
 }
 
+func (mc *MemcachedClient) getFile(key string, url string) ([]byte, bool) {
+	var file []byte
+	if !mc.get(key, url, &file) {
+		return nil, false
+	}
+	return file, true
+}
+
+// get decodes the cached value into the value. Values are stored json encoded by the set function.
+func (mc *MemcachedClient) get(key string, url string, value any) bool {
+	item, ok := mc.getItem(key, url)
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(item, value); err != nil {
+		slog.Error("failed to decode cached file.", slog.String("key", key), slog.String("err", err.Error()))
+		return false
+	}
+
+	return true
+}
+
+// getItem returns the raw cached value.
+func (mc *MemcachedClient) getItem(key string, url string) ([]byte, bool) {
+	item, err := mc.client.Get(key)
+	if err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			slog.Debug("cache not found.", slog.String("key", key), slog.String("url", url))
+			return nil, false
+		} else {
+			slog.Error("failed to check if cached.", slog.String("key", key), slog.String("url", url),
+				slog.String("err", err.Error()))
+			return nil, false
+		}
+	}
+	slog.Debug("cache found.", slog.String("key", key))
+
+	return item.Value, true
+}
+
 func (mc *MemcachedClient) set(key string, value any, expiration int32) error {
 	byteValue, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
+
+	return mc.setItem(key, byteValue, expiration)
+}
+
+func (mc *MemcachedClient) setItem(key string, value []byte, expiration int32) error {
 	item := &memcache.Item{
 		Key:        key,
-		Value:      byteValue,
+		Value:      value,
 		Expiration: expiration,
 	}
 
 	return mc.client.Set(item)
 }
 
-func (mc *MemcachedClient) generateDomainHash(url string) string {
+// generateOriginHash returns the key of the file of the url origin. The origin is the scheme, the canonical host and
+// the non-default port, so the files of http, https and other ports of the same host are cached separately.
+func generateOriginHash(url string, suffix string) string {
 	var key string
-	domain, err := util.GetDomain(url)
+	origin, err := util.GetBaseUrl(url)
 	if err != nil {
 		slog.Error("failed to parse url. Use full url as a key.", slog.String("url", url),
 			slog.String("err", err.Error()))
-		key = fmt.Sprintf("%s-robots-txt", hashURL(url))
+		key = fmt.Sprintf("%s-%s", hashURL(url), suffix)
 	} else {
-		key = fmt.Sprintf("%s-robots-txt", hashURL(domain))
+		key = fmt.Sprintf("%s-%s", hashURL(origin), suffix)
 		slog.Debug("key created.", slog.String("key:", key))
 	}
 
@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_generateOriginHash(t *testing.T) {
+	key := generateOriginHash("https://example.com/robots.txt", robotsTxtKeySuffix)
+
+	// the same origin
+	for _, url := range []string{"https://Example.COM:443/page", "HTTPS://example.com./", "https://example.com"} {
+		assert.Equal(t, key, generateOriginHash(url, robotsTxtKeySuffix), url)
+	}
+	// another scheme, port or file
+	assert.NotEqual(t, key, generateOriginHash("http://example.com/robots.txt", robotsTxtKeySuffix))
+	assert.NotEqual(t, key, generateOriginHash("https://example.com:8443/robots.txt", robotsTxtKeySuffix))
+	assert.NotEqual(t, key, generateOriginHash("https://example.com/robots.txt", aiTxtKeySuffix))
+	assert.Equal(t, generateOriginHash("http://example.com:80/", robotsTxtKeySuffix),
+		generateOriginHash("http://example.com/robots.txt", robotsTxtKeySuffix))
+}
@@ -2,7 +2,10 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	model "github.com/IliaW/rule-api/internal/model"
+	mock "github.com/stretchr/testify/mock"
+)
 
 // CachedClient is an autogenerated mock type for the CachedClient type
 type CachedClient struct {
@@ -14,14 +17,104 @@ func (_m *CachedClient) Close() {
 	_m.Called()
 }
 
+// GetAiTxtFile provides a mock function with given fields: _a0
+func (_m *CachedClient) GetAiTxtFile(_a0 string) ([]byte, bool) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAiTxtFile")
+	}
+
+	var r0 []byte
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) ([]byte, bool)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// GetPageDirectives provides a mock function with given fields: _a0
+func (_m *CachedClient) GetPageDirectives(_a0 string) ([]byte, bool) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPageDirectives")
+	}
+
+	var r0 []byte
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) ([]byte, bool)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
 // GetRobotsFile provides a mock function with given fields: _a0
-func (_m *CachedClient) GetRobotsFile(_a0 string) ([]byte, bool) {
+func (_m *CachedClient) GetRobotsFile(_a0 string) (*model.RobotsFile, bool) {
 	ret := _m.Called(_a0)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetRobotsFile")
 	}
 
+	var r0 *model.RobotsFile
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) (*model.RobotsFile, bool)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.RobotsFile); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RobotsFile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// GetTdmRepFile provides a mock function with given fields: _a0
+func (_m *CachedClient) GetTdmRepFile(_a0 string) ([]byte, bool) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTdmRepFile")
+	}
+
 	var r0 []byte
 	var r1 bool
 	if rf, ok := ret.Get(0).(func(string) ([]byte, bool)); ok {
@@ -44,8 +137,23 @@ func (_m *CachedClient) GetRobotsFile(_a0 string) ([]byte, bool) {
 	return r0, r1
 }
 
+// SaveAiTxtFile provides a mock function with given fields: _a0, _a1
+func (_m *CachedClient) SaveAiTxtFile(_a0 string, _a1 []byte) {
+	_m.Called(_a0, _a1)
+}
+
+// SavePageDirectives provides a mock function with given fields: _a0, _a1
+func (_m *CachedClient) SavePageDirectives(_a0 string, _a1 []byte) {
+	_m.Called(_a0, _a1)
+}
+
 // SaveRobotsFile provides a mock function with given fields: _a0, _a1
-func (_m *CachedClient) SaveRobotsFile(_a0 string, _a1 []byte) {
+func (_m *CachedClient) SaveRobotsFile(_a0 string, _a1 *model.RobotsFile) {
+	_m.Called(_a0, _a1)
+}
+
+// SaveTdmRepFile provides a mock function with given fields: _a0, _a1
+func (_m *CachedClient) SaveTdmRepFile(_a0 string, _a1 []byte) {
 	_m.Called(_a0, _a1)
 }
 
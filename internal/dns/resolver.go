@@ -0,0 +1,304 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// maxCachedHosts limits the memory used by the cache. The cache is cleaned up when the limit is reached.
+const maxCachedHosts = 100_000
+
+type DialFunc = func(ctx context.Context, network string, address string) (net.Conn, error)
+
+type Options struct {
+	// Server is the address of the DNS server, e.g. '10.0.0.2:53'. The system resolver is used if empty.
+	Server string
+	// PositiveTtl is how long resolved addresses are cached. Zero disables the cache.
+	PositiveTtl time.Duration
+	// NegativeTtl is how long lookup failures are cached. Zero disables the cache of failures.
+	NegativeTtl time.Duration
+	// HappyEyeballs races IPv6 and IPv4 connections (RFC 6555). Otherwise, the addresses are dialed one by one.
+	HappyEyeballs bool
+	// FallbackDelay is the delay before the connection to the other address family is started.
+	FallbackDelay time.Duration
+	// OnLookup is called after every lookup that is not served from the cache. May be nil.
+	OnLookup func(host string, latency time.Duration, err error)
+}
+
+// failure reasons of the lookups. The set is bounded, so the reason can be used as a metric attribute
+const (
+	FailureNotFound  = "not_found"
+	FailureTimeout   = "timeout"
+	FailureTemporary = "temporary"
+	FailureOther     = "other"
+)
+
+// FailureReason returns the reason of the failed lookup.
+func FailureReason(err error) string {
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		return FailureNotFound
+	case errors.Is(err, context.DeadlineExceeded) || errors.As(err, &dnsErr) && dnsErr.IsTimeout:
+		return FailureTimeout
+	case errors.As(err, &dnsErr) && dnsErr.IsTemporary:
+		return FailureTemporary
+	default:
+		return FailureOther
+	}
+}
+
+// Resolver resolves hostnames with an in-process cache and dials the resolved addresses.
+type Resolver struct {
+	opts     Options
+	lookup   func(ctx context.Context, network string, host string) ([]netip.Addr, error)
+	mu       sync.Mutex
+	cache    map[string]*entry
+	inflight map[string]*call
+}
+
+type entry struct {
+	addrs   []netip.Addr
+	err     error
+	expires time.Time
+}
+
+type call struct {
+	done  chan struct{}
+	addrs []netip.Addr
+	err   error
+}
+
+func NewResolver(opts Options) *Resolver {
+	resolver := net.DefaultResolver
+	if opts.Server != "" {
+		dialer := &net.Dialer{}
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network string, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, opts.Server)
+			},
+		}
+	}
+	if opts.FallbackDelay <= 0 {
+		opts.FallbackDelay = 300 * time.Millisecond
+	}
+
+	return &Resolver{
+		opts:     opts,
+		lookup:   resolver.LookupNetIP,
+		cache:    make(map[string]*entry),
+		inflight: make(map[string]*call),
+	}
+}
+
+// LookupNetIP returns the addresses of the host. Concurrent lookups of the same host share one DNS request.
+func (r *Resolver) LookupNetIP(ctx context.Context, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+
+	r.mu.Lock()
+	if e, ok := r.cache[host]; ok && time.Now().Before(e.expires) {
+		r.mu.Unlock()
+		return e.addrs, e.err
+	}
+	if l, ok := r.inflight[host]; ok {
+		r.mu.Unlock()
+		select {
+		case <-l.done:
+			return l.addrs, l.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	l := &call{done: make(chan struct{})}
+	r.inflight[host] = l
+	r.mu.Unlock()
+
+	start := time.Now()
+	// the lookup is shared, so it is not canceled with the context of the first caller
+	lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lookupTimeout(ctx))
+	l.addrs, l.err = r.lookup(lookupCtx, "ip", host)
+	cancel()
+	if r.opts.OnLookup != nil {
+		r.opts.OnLookup(host, time.Since(start), l.err)
+	}
+
+	r.mu.Lock()
+	delete(r.inflight, host)
+	r.store(host, l.addrs, l.err)
+	r.mu.Unlock()
+	close(l.done)
+
+	return l.addrs, l.err
+}
+
+// DialContext returns the dial function that resolves the host with the resolver and dials the addresses with dial.
+func (r *Resolver) DialContext(dial DialFunc) DialFunc {
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := r.LookupNetIP(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		addrs = filterNetwork(addrs, network)
+		if len(addrs) == 0 {
+			return nil, &net.DNSError{Err: "no suitable address found", Name: host, IsNotFound: true}
+		}
+		if !r.opts.HappyEyeballs {
+			return dialSerial(ctx, dial, network, addrs, port)
+		}
+
+		return r.dialParallel(ctx, dial, network, addrs, port)
+	}
+}
+
+func (r *Resolver) store(host string, addrs []netip.Addr, err error) {
+	ttl := r.opts.PositiveTtl
+	if err != nil {
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || dnsErr.IsTimeout {
+			return // timeouts and canceled lookups are not cached
+		}
+		ttl = r.opts.NegativeTtl
+	}
+	if ttl <= 0 {
+		return
+	}
+	if len(r.cache) >= maxCachedHosts {
+		now := time.Now()
+		for h, e := range r.cache {
+			if now.After(e.expires) {
+				delete(r.cache, h)
+			}
+		}
+		if len(r.cache) >= maxCachedHosts {
+			r.cache = make(map[string]*entry)
+		}
+	}
+	r.cache[host] = &entry{addrs: addrs, err: err, expires: time.Now().Add(ttl)}
+}
+
+// dialParallel dials the addresses of the first address family and starts dialing the other family after the
+// fallback delay. The first established connection wins.
+func (r *Resolver) dialParallel(ctx context.Context, dial DialFunc, network string, addrs []netip.Addr,
+	port string) (net.Conn, error) {
+	var primaries, fallbacks []netip.Addr
+	for _, addr := range addrs {
+		if addr.Is4() == addrs[0].Is4() {
+			primaries = append(primaries, addr)
+		} else {
+			fallbacks = append(fallbacks, addr)
+		}
+	}
+	if len(fallbacks) == 0 {
+		return dialSerial(ctx, dial, network, primaries, port)
+	}
+
+	type result struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan result)
+	race := func(addrs []netip.Addr, primary bool) {
+		conn, err := dialSerial(ctx, dial, network, addrs, port)
+		select {
+		case results <- result{conn: conn, err: err, primary: primary}:
+		case <-ctx.Done():
+			if conn != nil {
+				_ = conn.Close()
+			}
+		}
+	}
+	go race(primaries, true)
+	fallbackTimer := time.NewTimer(r.opts.FallbackDelay)
+	defer fallbackTimer.Stop()
+
+	var firstErr error
+	running := 1
+	fallbackStarted := false
+	startFallback := func() {
+		if !fallbackStarted {
+			fallbackStarted = true
+			running++
+			go race(fallbacks, false)
+		}
+	}
+	for {
+		select {
+		case <-fallbackTimer.C:
+			startFallback()
+		case res := <-results:
+			running--
+			if res.err == nil {
+				return res.conn, nil
+			}
+			if firstErr == nil || res.primary {
+				firstErr = res.err
+			}
+			// the primary family failed, the fallback is started at once
+			startFallback()
+			if running == 0 {
+				return nil, firstErr
+			}
+		}
+	}
+}
+
+func dialSerial(ctx context.Context, dial DialFunc, network string, addrs []netip.Addr, port string) (net.Conn,
+	error) {
+	var firstErr error
+	for _, addr := range addrs {
+		conn, err := dial(ctx, network, net.JoinHostPort(addr.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, firstErr
+}
+
+func filterNetwork(addrs []netip.Addr, network string) []netip.Addr {
+	switch network {
+	case "tcp4", "udp4":
+		return filter(addrs, func(addr netip.Addr) bool { return addr.Unmap().Is4() })
+	case "tcp6", "udp6":
+		return filter(addrs, func(addr netip.Addr) bool { return addr.Is6() && !addr.Is4In6() })
+	default:
+		return addrs
+	}
+}
+
+func filter(addrs []netip.Addr, keep func(addr netip.Addr) bool) []netip.Addr {
+	filtered := make([]netip.Addr, 0, len(addrs))
+	for _, addr := range addrs {
+		if keep(addr) {
+			filtered = append(filtered, addr)
+		}
+	}
+	return filtered
+}
+
+func lookupTimeout(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline)
+	}
+	return 10 * time.Second
+}
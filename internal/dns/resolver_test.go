@@ -0,0 +1,144 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LookupNetIP_Cache(t *testing.T) {
+	var lookups atomic.Int32
+	resolver := NewResolver(Options{PositiveTtl: time.Minute, NegativeTtl: time.Minute})
+	resolver.lookup = func(ctx context.Context, network string, host string) ([]netip.Addr, error) {
+		lookups.Add(1)
+		switch host {
+		case "example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.215.14")}, nil
+		case "timeout.example.com":
+			return nil, &net.DNSError{Err: "i/o timeout", Name: host, IsTimeout: true}
+		default:
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		addrs, err := resolver.LookupNetIP(context.Background(), "example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, []netip.Addr{netip.MustParseAddr("93.184.215.14")}, addrs)
+		_, err = resolver.LookupNetIP(context.Background(), "missing.example.com")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(2), lookups.Load())
+
+	// timeouts are not cached
+	for i := 0; i < 2; i++ {
+		_, err := resolver.LookupNetIP(context.Background(), "timeout.example.com")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(4), lookups.Load())
+
+	// ip addresses are not resolved
+	addrs, err := resolver.LookupNetIP(context.Background(), "::1")
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Addr{netip.IPv6Loopback()}, addrs)
+	assert.Equal(t, int32(4), lookups.Load())
+
+	// expired entries are resolved again
+	resolver.cache["example.com"].expires = time.Now().Add(-time.Second)
+	_, _ = resolver.LookupNetIP(context.Background(), "example.com")
+	assert.Equal(t, int32(5), lookups.Load())
+}
+
+func Test_DialContext(t *testing.T) {
+	addrs := []netip.Addr{
+		netip.MustParseAddr("2001:db8::1"),
+		netip.MustParseAddr("2001:db8::2"),
+		netip.MustParseAddr("192.0.2.1"),
+	}
+	tests := []struct {
+		name          string
+		happyEyeballs bool
+		reachable     map[string]bool
+		expected      string
+		isError       bool
+	}{
+		{
+			name:      "serial dial uses the next address",
+			reachable: map[string]bool{"192.0.2.1": true},
+			expected:  "192.0.2.1",
+		},
+		{
+			name:          "ipv6 is preferred",
+			happyEyeballs: true,
+			reachable:     map[string]bool{"2001:db8::1": true, "192.0.2.1": true},
+			expected:      "2001:db8::1",
+		},
+		{
+			name:          "ipv4 fallback when ipv6 hangs",
+			happyEyeballs: true,
+			reachable:     map[string]bool{"192.0.2.1": true},
+			expected:      "192.0.2.1",
+		},
+		{
+			name:          "all addresses are unreachable",
+			happyEyeballs: true,
+			reachable:     map[string]bool{},
+			isError:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			resolver := NewResolver(Options{
+				HappyEyeballs: test.happyEyeballs,
+				FallbackDelay: 10 * time.Millisecond,
+			})
+			resolver.lookup = func(ctx context.Context, network string, host string) ([]netip.Addr, error) {
+				return addrs, nil
+			}
+			dial := func(ctx context.Context, network string, address string) (net.Conn, error) {
+				host, _, _ := net.SplitHostPort(address)
+				if test.reachable[host] {
+					conn, _ := net.Pipe()
+					return &addrConn{Conn: conn, host: host}, nil
+				}
+				if test.happyEyeballs && netip.MustParseAddr(host).Is6() {
+					// the ipv6 connection hangs
+					select {
+					case <-ctx.Done():
+					case <-time.After(100 * time.Millisecond):
+					}
+				}
+				return nil, errors.New("connection refused")
+			}
+
+			conn, err := resolver.DialContext(dial)(context.Background(), "tcp", "example.com:443")
+			if test.isError {
+				assert.Error(tt, err)
+				return
+			}
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.expected, conn.(*addrConn).host)
+			_ = conn.Close()
+		})
+	}
+}
+
+type addrConn struct {
+	net.Conn
+	host string
+}
+
+func Test_FailureReason(t *testing.T) {
+	assert.Equal(t, FailureNotFound, FailureReason(&net.DNSError{Err: "no such host", IsNotFound: true}))
+	assert.Equal(t, FailureTimeout, FailureReason(&net.DNSError{Err: "i/o timeout", IsTimeout: true}))
+	assert.Equal(t, FailureTimeout, FailureReason(context.DeadlineExceeded))
+	assert.Equal(t, FailureTemporary, FailureReason(&net.DNSError{Err: "server misbehaving", IsTemporary: true}))
+	assert.Equal(t, FailureOther, FailureReason(errors.New("boom")))
+}
@@ -0,0 +1,207 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/IliaW/rule-api/config"
+	"github.com/IliaW/rule-api/internal/model"
+	rulev1 "github.com/IliaW/rule-api/proto/rule/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultMaxBatchSize     = 100
+	defaultBatchConcurrency = 10
+)
+
+// Checker makes the crawl verdict. It is implemented by handler.RuleApiHandler.
+type Checker interface {
+	CheckCrawl(ctx context.Context, url string, userAgent string, purpose string) (model.AllowedCrawlResponse, int)
+}
+
+// Server is the grpc server with the crawl, health and reflection services.
+type Server struct {
+	grpcServer   *grpc.Server
+	healthServer *health.Server
+}
+
+func NewServer(cfg *config.GrpcConfig, checker Checker) *Server {
+	crawlServer := &crawlServer{
+		checker:          checker,
+		maxBatchSize:     cfg.MaxBatchSize,
+		batchConcurrency: cfg.BatchConcurrency,
+	}
+	if crawlServer.maxBatchSize <= 0 {
+		crawlServer.maxBatchSize = defaultMaxBatchSize
+	}
+	if crawlServer.batchConcurrency <= 0 {
+		crawlServer.batchConcurrency = defaultBatchConcurrency
+	}
+
+	grpcServer := grpc.NewServer()
+	rulev1.RegisterCrawlServiceServer(grpcServer, crawlServer)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(rulev1.CrawlService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	if cfg.Reflection {
+		reflection.Register(grpcServer)
+	}
+
+	return &Server{
+		grpcServer:   grpcServer,
+		healthServer: healthServer,
+	}
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	return s.grpcServer.Serve(listener)
+}
+
+// Stop marks the services as not serving and waits for the running calls to finish.
+func (s *Server) Stop(ctx context.Context) {
+	s.healthServer.Shutdown()
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		slog.Warn("grpc graceful stop timeout exceeded. Stopping the server.")
+		s.grpcServer.Stop()
+	}
+}
+
+type crawlServer struct {
+	rulev1.UnimplementedCrawlServiceServer
+	checker          Checker
+	maxBatchSize     int
+	batchConcurrency int
+}
+
+func (s *crawlServer) CheckCrawl(ctx context.Context, req *rulev1.CheckCrawlRequest) (*rulev1.CheckCrawlResponse,
+	error) {
+	return s.check(ctx, req), nil
+}
+
+// CheckCrawlBatch checks the urls concurrently, at most 'batch_concurrency' at a time.
+func (s *crawlServer) CheckCrawlBatch(ctx context.Context, req *rulev1.CheckCrawlBatchRequest) (
+	*rulev1.CheckCrawlBatchResponse, error) {
+	if len(req.GetRequests()) > s.maxBatchSize {
+		return nil, status.Error(codes.InvalidArgument,
+			fmt.Sprintf("the batch has %d requests. The limit is %d", len(req.GetRequests()), s.maxBatchSize))
+	}
+
+	responses := make([]*rulev1.CheckCrawlResponse, len(req.GetRequests()))
+	semaphore := make(chan struct{}, s.batchConcurrency)
+	var wg sync.WaitGroup
+	for i, checkReq := range req.GetRequests() {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			responses[i] = s.check(ctx, checkReq)
+		}()
+	}
+	wg.Wait()
+
+	return &rulev1.CheckCrawlBatchResponse{Responses: responses}, nil
+}
+
+func (s *crawlServer) CheckCrawlStream(stream grpc.BidiStreamingServer[rulev1.CheckCrawlRequest,
+	rulev1.CheckCrawlResponse]) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = stream.Send(s.check(stream.Context(), req)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *crawlServer) check(ctx context.Context, req *rulev1.CheckCrawlRequest) *rulev1.CheckCrawlResponse {
+	resp, _ := s.checker.CheckCrawl(ctx, req.GetUrl(), req.GetUserAgent(), req.GetPurpose())
+	return toProto(req, &resp)
+}
+
+func toProto(req *rulev1.CheckCrawlRequest, resp *model.AllowedCrawlResponse) *rulev1.CheckCrawlResponse {
+	result := &rulev1.CheckCrawlResponse{
+		Url:               req.GetUrl(),
+		UserAgent:         req.GetUserAgent(),
+		IsAllowed:         resp.IsAllowed,
+		Blocked:           resp.Blocked,
+		StatusCode:        int32(resp.StatusCode),
+		Error:             resp.Error,
+		ErrorCode:         resp.ErrorCode,
+		ResolvedOrigin:    resp.ResolvedOrigin,
+		MatchedAlias:      resp.MatchedAlias,
+		RegistrableDomain: resp.RegistrableDomain,
+		Source:            resp.Source,
+		RuleId:            int64(resp.RuleId),
+		RobotsUrl:         resp.RobotsUrl,
+		FetchedAt:         timestampOrNil(resp.FetchedAt),
+		ExpiresAt:         timestampOrNil(resp.ExpiresAt),
+	}
+	if resp.AiTxt != nil {
+		result.AiTxt = &rulev1.AiTxtVerdict{
+			IsAllowed:  resp.AiTxt.IsAllowed,
+			Found:      resp.AiTxt.Found,
+			StatusCode: int32(resp.AiTxt.StatusCode),
+			Error:      resp.AiTxt.Error,
+			ErrorCode:  resp.AiTxt.ErrorCode,
+		}
+	}
+	for _, verdict := range resp.TokenVerdicts {
+		result.TokenVerdicts = append(result.TokenVerdicts, &rulev1.TokenVerdict{
+			Token:     verdict.Token,
+			IsAllowed: verdict.IsAllowed,
+		})
+	}
+	if resp.Content != nil {
+		result.Content = &rulev1.RobotsContent{
+			ContentType:     resp.Content.ContentType,
+			ContentEncoding: resp.Content.ContentEncoding,
+			Charset:         resp.Content.Charset,
+			Bom:             resp.Content.Bom,
+			Detected:        resp.Content.Detected,
+			Valid:           resp.Content.Valid,
+			Policy:          resp.Content.Policy,
+		}
+	}
+
+	return result
+}
+
+func timestampOrNil(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
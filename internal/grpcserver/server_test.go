@@ -0,0 +1,145 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/IliaW/rule-api/config"
+	"github.com/IliaW/rule-api/internal/model"
+	rulev1 "github.com/IliaW/rule-api/proto/rule/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type checkerFunc func(url string, userAgent string, purpose string) (model.AllowedCrawlResponse, int)
+
+func (f checkerFunc) CheckCrawl(_ context.Context, url string, userAgent string,
+	purpose string) (model.AllowedCrawlResponse, int) {
+	return f(url, userAgent, purpose)
+}
+
+var fetchedAt = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func testChecker(url string, userAgent string, _ string) (model.AllowedCrawlResponse, int) {
+	if url == "" {
+		return model.AllowedCrawlResponse{StatusCode: http.StatusBadRequest, Error: "'url' query parameter is required",
+			ErrorCode: model.ErrorCodeInvalidUrl}, http.StatusBadRequest
+	}
+	return model.AllowedCrawlResponse{
+		IsAllowed:  userAgent != "bad-bot",
+		StatusCode: http.StatusOK,
+		Source:     model.SourceLive,
+		RobotsUrl:  "https://example.com/robots.txt",
+		FetchedAt:  &fetchedAt,
+		Content:    &model.RobotsContent{ContentType: "text/plain", Detected: "robots.txt", Valid: true},
+		AiTxt: &model.AiTxtVerdict{IsAllowed: true, StatusCode: http.StatusServiceUnavailable,
+			Error: "failed to fetch ai.txt", ErrorCode: model.ErrorCodeRobots5xx},
+	}, http.StatusOK
+}
+
+func newTestClient(t *testing.T, cfg *config.GrpcConfig) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewServer(cfg, checkerFunc(testChecker))
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		server.Stop(context.Background())
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func Test_CheckCrawl(t *testing.T) {
+	client := rulev1.NewCrawlServiceClient(newTestClient(t, &config.GrpcConfig{}))
+
+	resp, err := client.CheckCrawl(context.Background(),
+		&rulev1.CheckCrawlRequest{Url: "https://example.com/test", UserAgent: "bot"})
+	require.NoError(t, err)
+	assert.True(t, resp.GetIsAllowed())
+	assert.Equal(t, "https://example.com/test", resp.GetUrl())
+	assert.Equal(t, int32(http.StatusOK), resp.GetStatusCode())
+	assert.Equal(t, model.SourceLive, resp.GetSource())
+	assert.Equal(t, fetchedAt, resp.GetFetchedAt().AsTime())
+	assert.Nil(t, resp.GetExpiresAt())
+	assert.Equal(t, "robots.txt", resp.GetContent().GetDetected())
+	assert.Equal(t, model.ErrorCodeRobots5xx, resp.GetAiTxt().GetErrorCode())
+
+	// errors are returned in the response
+	resp, err = client.CheckCrawl(context.Background(), &rulev1.CheckCrawlRequest{UserAgent: "bot"})
+	require.NoError(t, err)
+	assert.False(t, resp.GetIsAllowed())
+	assert.Equal(t, int32(http.StatusBadRequest), resp.GetStatusCode())
+	assert.Equal(t, model.ErrorCodeInvalidUrl, resp.GetErrorCode())
+}
+
+func Test_CheckCrawlBatch(t *testing.T) {
+	client := rulev1.NewCrawlServiceClient(newTestClient(t, &config.GrpcConfig{MaxBatchSize: 3,
+		BatchConcurrency: 2}))
+
+	resp, err := client.CheckCrawlBatch(context.Background(), &rulev1.CheckCrawlBatchRequest{
+		Requests: []*rulev1.CheckCrawlRequest{
+			{Url: "https://example.com/1", UserAgent: "bot"},
+			{Url: "https://example.com/2", UserAgent: "bad-bot"},
+			{Url: "https://example.com/3", UserAgent: "bot"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GetResponses(), 3)
+	for i, expected := range []bool{true, false, true} {
+		assert.Equal(t, expected, resp.GetResponses()[i].GetIsAllowed())
+	}
+	assert.Equal(t, "https://example.com/2", resp.GetResponses()[1].GetUrl())
+
+	_, err = client.CheckCrawlBatch(context.Background(), &rulev1.CheckCrawlBatchRequest{
+		Requests: make([]*rulev1.CheckCrawlRequest, 4),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func Test_CheckCrawlStream(t *testing.T) {
+	client := rulev1.NewCrawlServiceClient(newTestClient(t, &config.GrpcConfig{}))
+
+	stream, err := client.CheckCrawlStream(context.Background())
+	require.NoError(t, err)
+	for _, userAgent := range []string{"bot", "bad-bot"} {
+		require.NoError(t, stream.Send(&rulev1.CheckCrawlRequest{Url: "https://example.com/", UserAgent: userAgent}))
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, userAgent, resp.GetUserAgent())
+		assert.Equal(t, userAgent == "bot", resp.GetIsAllowed())
+	}
+	require.NoError(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func Test_Health(t *testing.T) {
+	client := healthpb.NewHealthClient(newTestClient(t, &config.GrpcConfig{}))
+
+	for _, service := range []string{"", rulev1.CrawlService_ServiceDesc.ServiceName} {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	}
+}
@@ -0,0 +1,321 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IliaW/rule-api/config"
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/internal/persistence"
+	"github.com/IliaW/rule-api/util"
+)
+
+const (
+	defaultWorkers      = 1
+	defaultConcurrency  = 20
+	defaultChunkSize    = 1000
+	defaultPollInterval = 5 * time.Second
+	defaultStaleAfter   = 2 * time.Minute
+	defaultRetention    = 7 * 24 * time.Hour
+	// purgeInterval is how often the expired jobs are deleted
+	purgeInterval = time.Hour
+	// MaxLineSize is the max size of one url in the uploaded list
+	MaxLineSize = 16 * 1024
+)
+
+// Checker checks the urls of the same origin. The verdicts are in the order of the urls.
+type Checker interface {
+	CheckCrawlGroup(ctx context.Context, urls []string, userAgent string, purpose string) []model.AllowedCrawlResponse
+}
+
+// Runner processes the queued jobs in the background. The url lists and the results are stored in the database, so
+// any instance runs any job. The progress is saved after each chunk of the urls, so the job is resumed from the last
+// checkpoint after a restart. The finished jobs are deleted with their results after the retention time.
+type Runner struct {
+	cfg     *config.JobConfig
+	storage persistence.JobStorage
+	checker Checker
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func NewRunner(cfg *config.JobConfig, storage persistence.JobStorage, checker Checker) *Runner {
+	return &Runner{
+		cfg:     cfg,
+		storage: storage,
+		checker: checker,
+	}
+}
+
+// Start starts the workers and the purge of the expired jobs. They run until Stop is called.
+func (r *Runner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			r.purge()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(purgeInterval):
+			}
+		}
+	}()
+	for range orDefault(r.cfg.Workers, defaultWorkers) {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.work(ctx)
+		}()
+	}
+}
+
+// Stop stops the workers and waits for them until the context is done. The running jobs are queued again.
+func (r *Runner) Stop(ctx context.Context) {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		slog.Info("job workers stopped.")
+	case <-ctx.Done():
+		slog.Warn("job workers did not stop in time.")
+	}
+}
+
+func (r *Runner) work(ctx context.Context) {
+	pollInterval := orDefault(r.cfg.PollInterval, defaultPollInterval)
+	staleAfter := orDefault(r.cfg.StaleAfter, defaultStaleAfter)
+	for ctx.Err() == nil {
+		job, err := r.storage.ClaimJob(staleAfter)
+		if err != nil {
+			slog.Error("failed to claim job.", slog.String("err", err.Error()))
+		}
+		if job != nil {
+			r.run(ctx, job)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// purge deletes the jobs finished more than the retention time ago. Every instance purges, the delete is idempotent.
+func (r *Runner) purge() {
+	deleted, err := r.storage.DeleteFinishedJobs(orDefault(r.cfg.Retention, defaultRetention))
+	if err != nil {
+		slog.Error("failed to delete expired jobs.", slog.String("err", err.Error()))
+		return
+	}
+	if deleted > 0 {
+		slog.Info("expired jobs deleted.", slog.Int64("deleted", deleted))
+	}
+}
+
+func (r *Runner) run(ctx context.Context, job *model.Job) {
+	slog.Info("job started.", slog.String("id", job.ID), slog.Int("processed", job.Processed),
+		slog.Int("total", job.Total))
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	stopHeartbeat := r.heartbeat(jobCtx, job, cancel)
+	err := r.process(jobCtx, job)
+	stopHeartbeat()
+	if cause := context.Cause(jobCtx); errors.Is(cause, persistence.ErrLeaseLost) {
+		err = cause
+	}
+	switch {
+	case err == nil:
+		if err = r.storage.FinishJob(job.ID, job.Lease, model.JobStatusCompleted, ""); err != nil {
+			slog.Error("failed to complete job.", slog.String("id", job.ID), slog.String("err", err.Error()))
+			return
+		}
+		slog.Info("job completed.", slog.String("id", job.ID))
+	case errors.Is(err, persistence.ErrLeaseLost):
+		// the job is run by another worker, it is not changed by this one anymore
+		slog.Warn("job lease lost.", slog.String("id", job.ID), slog.String("err", err.Error()))
+	case errors.Is(err, context.Canceled):
+		// the job is resumed from the last checkpoint by the next worker
+		if err = r.storage.RequeueJob(job.ID, job.Lease); err != nil {
+			slog.Error("failed to requeue job.", slog.String("id", job.ID), slog.String("err", err.Error()))
+			return
+		}
+		slog.Info("job requeued.", slog.String("id", job.ID), slog.Int("processed", job.Processed))
+	default:
+		slog.Error("job failed.", slog.String("id", job.ID), slog.String("err", err.Error()))
+		if err = r.storage.FinishJob(job.ID, job.Lease, model.JobStatusFailed, err.Error()); err != nil {
+			slog.Error("failed to save job status.", slog.String("id", job.ID), slog.String("err", err.Error()))
+		}
+	}
+}
+
+// heartbeat marks the job as alive every third of the stale time until stop is called, so a long chunk does not
+// make the job stale. The job is canceled with ErrLeaseLost if it is taken over by another worker.
+func (r *Runner) heartbeat(ctx context.Context, job *model.Job, cancel context.CancelCauseFunc) (stop func()) {
+	interval := orDefault(r.cfg.StaleAfter, defaultStaleAfter) / 3
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := r.storage.HeartbeatJob(job.ID, job.Lease); err != nil {
+				if errors.Is(err, persistence.ErrLeaseLost) {
+					cancel(err)
+					return
+				}
+				slog.Error("failed to save job heartbeat.", slog.String("id", job.ID), slog.String("err", err.Error()))
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+func (r *Runner) process(ctx context.Context, job *model.Job) error {
+	chunkSize := orDefault(r.cfg.ChunkSize, defaultChunkSize)
+	var buf bytes.Buffer
+	for {
+		// the urls checked before the last checkpoint are skipped
+		chunk, err := r.storage.GetJobUrls(job.ID, job.Processed, chunkSize)
+		if err != nil {
+			return fmt.Errorf("failed to read the url list. %w", err)
+		}
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		responses, err := r.checkChunk(ctx, chunk, job.UserAgent, job.Purpose)
+		if err != nil {
+			return err
+		}
+		buf.Reset()
+		encoder := json.NewEncoder(&buf)
+		for i, resp := range responses {
+			switch {
+			case resp.ErrorCode != "":
+				job.Failed++
+			case resp.IsAllowed:
+				job.Allowed++
+			default:
+				job.Disallowed++
+			}
+			if err = encoder.Encode(model.CrawlStreamResult{
+				Index:                job.Processed + i,
+				Url:                  chunk[i],
+				UserAgent:            job.UserAgent,
+				AllowedCrawlResponse: resp,
+			}); err != nil {
+				return fmt.Errorf("failed to encode the result. %w", err)
+			}
+		}
+		chunkStart := job.Processed
+		job.Processed += len(chunk)
+		if err = r.storage.UpdateJobProgress(job, chunkStart, buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to save the job progress. %w", err)
+		}
+	}
+}
+
+// checkChunk checks the urls grouped by the origin, so the robots.txt file is resolved once per origin.
+// The groups are checked concurrently. The verdicts are in the order of the urls.
+func (r *Runner) checkChunk(ctx context.Context, urls []string, userAgent string,
+	purpose string) ([]model.AllowedCrawlResponse, error) {
+	groups := make(map[string][]int)
+	keys := make([]string, 0)
+	for i, url := range urls {
+		key := groupKey(url)
+		if key == "" {
+			// the invalid urls are checked one by one
+			key = fmt.Sprintf("#%d", i)
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	responses := make([]model.AllowedCrawlResponse, len(urls))
+	semaphore := make(chan struct{}, orDefault(r.cfg.Concurrency, defaultConcurrency))
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes := groups[key]
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			groupUrls := make([]string, len(indexes))
+			for i, index := range indexes {
+				groupUrls[i] = urls[index]
+			}
+			for i, resp := range r.checker.CheckCrawlGroup(ctx, groupUrls, userAgent, purpose) {
+				responses[indexes[i]] = resp
+			}
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		// the chunk is not saved, it is checked again when the job is resumed
+		return nil, ctx.Err()
+	}
+
+	return responses, nil
+}
+
+// groupKey returns the origin of the url. The urls without a scheme are grouped separately, since their scheme
+// is resolved by the checker. Returns an empty string for the invalid url.
+func groupKey(url string) string {
+	if util.HasScheme(url) {
+		baseUrl, err := util.GetBaseUrl(url)
+		if err != nil {
+			return ""
+		}
+		return baseUrl
+	}
+	domain, err := util.GetDomain("//" + strings.TrimPrefix(url, "//"))
+	if err != nil {
+		return ""
+	}
+	return "//" + domain
+}
+
+func orDefault[T int | time.Duration](value T, defaultValue T) T {
+	if value > 0 {
+		return value
+	}
+	return defaultValue
+}
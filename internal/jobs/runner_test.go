@@ -0,0 +1,174 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IliaW/rule-api/config"
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/IliaW/rule-api/internal/persistence"
+	storageMock "github.com/IliaW/rule-api/internal/persistence/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// groupChecker allows the urls with the '/allowed' path and records the checked groups.
+type groupChecker struct {
+	mu     sync.Mutex
+	groups [][]string
+}
+
+func (c *groupChecker) CheckCrawlGroup(_ context.Context, urls []string, _ string,
+	_ string) []model.AllowedCrawlResponse {
+	c.mu.Lock()
+	c.groups = append(c.groups, urls)
+	c.mu.Unlock()
+	responses := make([]model.AllowedCrawlResponse, len(urls))
+	for i, url := range urls {
+		switch {
+		case strings.Contains(url, "failed"):
+			responses[i] = model.AllowedCrawlResponse{StatusCode: http.StatusBadGateway,
+				Error: "failed to connect to the target", ErrorCode: model.ErrorCodeConnectionFailed}
+		default:
+			responses[i] = model.AllowedCrawlResponse{StatusCode: http.StatusOK,
+				IsAllowed: strings.HasSuffix(url, "/allowed")}
+		}
+	}
+	return responses
+}
+
+// urlStorage mocks the url list of the job in the storage.
+func urlStorage(t *testing.T, id string, urls string) *storageMock.JobStorage {
+	list := strings.Split(strings.TrimSuffix(urls, "\n"), "\n")
+	jobRepo := storageMock.NewJobStorage(t)
+	jobRepo.On("GetJobUrls", id, mock.Anything, mock.Anything).Return(func(_ string, offset int,
+		limit int) ([]string, error) {
+		offset = min(offset, len(list))
+		return list[offset:min(offset+limit, len(list))], nil
+	}).Maybe()
+	return jobRepo
+}
+
+func Test_Runner_process(t *testing.T) {
+	testSet := []struct {
+		name             string
+		urls             string
+		job              model.Job
+		expectedResult   string
+		expectedGroups   int
+		expectedProgress []int
+		expectedJob      model.Job
+	}{
+		{
+			name: "urls are grouped by origin and written in order",
+			urls: "https://example.com/allowed\nhttps://other.com/disallowed\nhttps://example.com/disallowed\n" +
+				"example.com/allowed\nhttps://failed.com/allowed\n",
+			job: model.Job{ID: "job", UserAgent: "test-bot"},
+			expectedResult: "{\"index\":0,\"url\":\"https://example.com/allowed\",\"user_agent\":\"test-bot\"," +
+				"\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"}\n" +
+				"{\"index\":1,\"url\":\"https://other.com/disallowed\",\"user_agent\":\"test-bot\"," +
+				"\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\"}\n" +
+				"{\"index\":2,\"url\":\"https://example.com/disallowed\",\"user_agent\":\"test-bot\"," +
+				"\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\"}\n" +
+				"{\"index\":3,\"url\":\"example.com/allowed\",\"user_agent\":\"test-bot\"," +
+				"\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"}\n" +
+				"{\"index\":4,\"url\":\"https://failed.com/allowed\",\"user_agent\":\"test-bot\"," +
+				"\"is_allowed\":false,\"blocked\":false,\"status_code\":502," +
+				"\"error\":\"failed to connect to the target\",\"error_code\":\"CONNECTION_FAILED\"}\n",
+			expectedGroups:   4,
+			expectedProgress: []int{0, 3},
+			expectedJob: model.Job{ID: "job", UserAgent: "test-bot", Processed: 5, Allowed: 2, Disallowed: 2,
+				Failed: 1},
+		},
+		{
+			name: "job is resumed from the checkpoint",
+			urls: "https://example.com/allowed\nhttps://example.com/disallowed\nhttps://other.com/allowed\n",
+			job:  model.Job{ID: "job", UserAgent: "test-bot", Processed: 1, Allowed: 1},
+			expectedResult: "{\"index\":1,\"url\":\"https://example.com/disallowed\",\"user_agent\":\"test-bot\"," +
+				"\"is_allowed\":false,\"blocked\":false,\"status_code\":200,\"error\":\"\"}\n" +
+				"{\"index\":2,\"url\":\"https://other.com/allowed\",\"user_agent\":\"test-bot\"," +
+				"\"is_allowed\":true,\"blocked\":false,\"status_code\":200,\"error\":\"\"}\n",
+			expectedGroups:   2,
+			expectedProgress: []int{1},
+			expectedJob:      model.Job{ID: "job", UserAgent: "test-bot", Processed: 3, Allowed: 2, Disallowed: 1},
+		},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			jobRepo := urlStorage(tt, test.job.ID, test.urls)
+			// the chunks are saved with the progress
+			progress := make([]int, 0)
+			var result strings.Builder
+			jobRepo.On("UpdateJobProgress", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				progress = append(progress, args.Int(1))
+				result.Write(args.Get(2).([]byte))
+			}).Return(nil)
+			checker := &groupChecker{}
+			runner := NewRunner(&config.JobConfig{ChunkSize: 3}, jobRepo, checker)
+
+			job := test.job
+			err := runner.process(context.Background(), &job)
+
+			require.NoError(tt, err)
+			assert.Equal(tt, test.expectedResult, result.String())
+			assert.Equal(tt, test.expectedGroups, len(checker.groups))
+			assert.Equal(tt, test.expectedProgress, progress)
+			assert.Equal(tt, test.expectedJob, job)
+		})
+	}
+}
+
+func Test_Runner_run(t *testing.T) {
+	jobRepo := urlStorage(t, "job", "https://example.com/allowed\n")
+	jobRepo.On("GetJobUrls", "broken", 0, mock.Anything).Return(nil, errors.New("connection refused")).Once()
+	jobRepo.On("UpdateJobProgress", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	jobRepo.On("RequeueJob", "job", "lease").Return(nil).Once()
+	jobRepo.On("FinishJob", "job", "lease", model.JobStatusCompleted, "").Return(nil).Once()
+	jobRepo.On("FinishJob", "broken", "lease", model.JobStatusFailed, mock.Anything).Return(nil).Once()
+	runner := NewRunner(&config.JobConfig{}, jobRepo, &groupChecker{})
+
+	// the stopped runner requeues the job without checking the urls
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	runner.run(ctx, &model.Job{ID: "job", UserAgent: "test-bot", Lease: "lease"})
+	runner.run(context.Background(), &model.Job{ID: "job", UserAgent: "test-bot", Lease: "lease"})
+	runner.run(context.Background(), &model.Job{ID: "broken", UserAgent: "test-bot", Lease: "lease"})
+}
+
+func Test_Runner_purge(t *testing.T) {
+	jobRepo := storageMock.NewJobStorage(t)
+	jobRepo.On("DeleteFinishedJobs", defaultRetention).Return(int64(2), nil).Once()
+	jobRepo.On("DeleteFinishedJobs", time.Hour).Return(int64(0), errors.New("connection refused")).Once()
+
+	// the default retention is used if it is not set
+	NewRunner(&config.JobConfig{}, jobRepo, &groupChecker{}).purge()
+	NewRunner(&config.JobConfig{Retention: time.Hour}, jobRepo, &groupChecker{}).purge()
+}
+
+// blockingChecker blocks until the check is canceled.
+type blockingChecker struct{}
+
+func (blockingChecker) CheckCrawlGroup(ctx context.Context, urls []string, _ string,
+	_ string) []model.AllowedCrawlResponse {
+	<-ctx.Done()
+	return make([]model.AllowedCrawlResponse, len(urls))
+}
+
+func Test_Runner_run_LeaseLost(t *testing.T) {
+	jobRepo := urlStorage(t, "job", "https://example.com/allowed\n")
+	jobRepo.On("HeartbeatJob", "job", "lease").Return(nil).Once()
+	jobRepo.On("HeartbeatJob", "job", "lease").
+		Return(fmt.Errorf("job with id 'job' %w", persistence.ErrLeaseLost)).Once()
+	runner := NewRunner(&config.JobConfig{StaleAfter: 30 * time.Millisecond}, jobRepo, blockingChecker{})
+
+	// the heartbeat keeps the job alive during the chunk. The job taken over by another worker is canceled and
+	// neither requeued nor finished
+	runner.run(context.Background(), &model.Job{ID: "job", UserAgent: "test-bot", Lease: "lease"})
+}
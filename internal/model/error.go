@@ -0,0 +1,12 @@
+package model
+
+// ErrorResponse godoc
+// @Description Error of the custom rule and domain alias endpoints. 'code' is one of: BAD_REQUEST, UNAUTHORIZED, FORBIDDEN, NOT_FOUND, CONFLICT, UNPROCESSABLE_ENTITY, INTERNAL_ERROR
+// @Type ErrorResponse
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Details is the cause of the client error, e.g. the validation error. It is not returned for the server errors
+	Details   string `json:"details,omitempty"`
+	RequestId string `json:"request_id,omitempty"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Job godoc
+// @Description The asynchronous crawl check of the uploaded url list. 'processed' is the number of the checked urls out of 'total'
+// @Type Job
+type Job struct {
+	ID         string `json:"id"`
+	Status     string `json:"status"`
+	UserAgent  string `json:"user_agent"`
+	Purpose    string `json:"purpose,omitempty"`
+	Total      int    `json:"total"`
+	Processed  int    `json:"processed"`
+	Allowed    int    `json:"allowed"`
+	Disallowed int    `json:"disallowed"`
+	// Failed is the number of the urls with an error, e.g. the robots.txt file could not be fetched
+	Failed int    `json:"failed"`
+	Error  string `json:"error,omitempty"`
+	// Lease is set by the worker that claimed the job. The job is updated only with it
+	Lease      string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
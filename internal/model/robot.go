@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"net/http"
+	"time"
+)
 
 // Rule godoc
 // @Description Represents a custom rule for a domain
@@ -14,6 +17,52 @@ type Rule struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// DomainAlias godoc
+// @Description Maps an alias domain to the domain of the custom rule, e.g. example.co.uk to example.com
+// @Type DomainAlias
+type DomainAlias struct {
+	ID        int       `json:"id"`
+	Alias     string    `json:"alias"`
+	Domain    string    `json:"domain"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RulePage godoc
+// @Description One page of the custom rules. 'next_cursor' is returned if there are more rules, pass it as 'cursor' to get the next page
+// @Type RulePage
+type RulePage struct {
+	Rules      []*Rule `json:"rules"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+const (
+	RuleSortUpdatedAt = "updated_at"
+	RuleSortDomain    = "domain"
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// RuleFilter selects the custom rules for the list. The empty fields are not filtered by. The date ranges are
+// inclusive.
+type RuleFilter struct {
+	// Domain and RobotsTxt are the case-insensitive substrings of the domain and the robots.txt content
+	Domain      string
+	RobotsTxt   string
+	Blocked     *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	// SortBy is RuleSortUpdatedAt or RuleSortDomain. The rules with the same key are sorted by the id
+	SortBy string
+	// Order is SortAsc or SortDesc
+	Order string
+	// After is the last rule of the previous page. Only the rules after it in the sort order are returned
+	After *Rule
+	Limit int
+}
+
 // AllowedCrawlResponse godoc
 // @Description Is crawl allowed for the domain
 // @Type AllowedCrawlResponse
@@ -22,9 +71,219 @@ type AllowedCrawlResponse struct {
 	Blocked    bool   `json:"blocked"`
 	StatusCode int    `json:"status_code"`
 	Error      string `json:"error"`
+	// ErrorCode is one of the ErrorCode constants. Error is the short message for it
+	ErrorCode     string         `json:"error_code,omitempty"`
+	AiTxt         *AiTxtVerdict  `json:"ai_txt,omitempty"`
+	TokenVerdicts []TokenVerdict `json:"token_verdicts,omitempty"`
+	// ResolvedOrigin is returned when the url is sent without a scheme
+	ResolvedOrigin string `json:"resolved_origin,omitempty"`
+	// MatchedAlias is the domain of the custom rule if the rule is found by the www or alias table fallback
+	MatchedAlias      string `json:"matched_alias,omitempty"`
+	RegistrableDomain string `json:"registrable_domain,omitempty"`
+	// Content is returned when the robots.txt file is fetched from the target
+	Content *RobotsContent `json:"content,omitempty"`
+	// Source is where the robots.txt file comes from: custom_rule, cache or live
+	Source string `json:"source,omitempty"`
+	RuleId int    `json:"rule_id,omitempty"`
+	// RobotsUrl is the url the robots.txt file is fetched from, after redirects
+	RobotsUrl string `json:"robots_url,omitempty"`
+	// FetchedAt is the fetch time of the robots.txt file or the update time of the custom rule
+	FetchedAt *time.Time `json:"fetched_at,omitempty"`
+	// ExpiresAt is the expiration time of the cached robots.txt file
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+const (
+	SourceCustomRule = "custom_rule"
+	SourceCache      = "cache"
+	SourceLive       = "live"
+)
+
+// error codes of the AllowedCrawlResponse, the ai.txt verdict, the TDM reservation and the page directives
+const (
+	ErrorCodeInvalidUrl       = "INVALID_URL"
+	ErrorCodeInvalidRequest   = "INVALID_REQUEST"
+	ErrorCodeDnsFailure       = "DNS_FAILURE"
+	ErrorCodeTimeout          = "TIMEOUT"
+	ErrorCodeTlsError         = "TLS_ERROR"
+	ErrorCodeConnectionFailed = "CONNECTION_FAILED"
+	ErrorCodeForbiddenAddress = "FORBIDDEN_ADDRESS"
+	ErrorCodeRobots4xx        = "ROBOTS_4XX"
+	ErrorCodeRobots5xx        = "ROBOTS_5XX"
+	ErrorCodeBodyTooLarge     = "BODY_TOO_LARGE"
+	ErrorCodeFetchFailed      = "FETCH_FAILED"
+	ErrorCodeInvalidTdmRep    = "INVALID_TDMREP"
+)
+
+// CrawlStreamRecord godoc
+// @Description One line of the NDJSON request body of /crawl-allowed/stream
+// @Type CrawlStreamRecord
+type CrawlStreamRecord struct {
+	Url       string `json:"url"`
+	UserAgent string `json:"user_agent"`
+	Purpose   string `json:"purpose,omitempty"`
+}
+
+// CrawlStreamResult godoc
+// @Description One line of the NDJSON response of /crawl-allowed/stream. 'index' is the zero-based line number of the record in the request body, ignoring empty lines
+// @Type CrawlStreamResult
+type CrawlStreamResult struct {
+	Index     int    `json:"index"`
+	Url       string `json:"url"`
+	UserAgent string `json:"user_agent"`
+	AllowedCrawlResponse
+}
+
+// RobotsFile is the fetched robots.txt file with the fetch metadata. It is stored in the cache.
+type RobotsFile struct {
+	StatusCode int
+	Url        string
+	Body       []byte
+	Content    *RobotsContent
+	FetchedAt  time.Time
+	ExpiresAt  time.Time
+	// ETag and LastModified are the validators of the response
+	ETag         string
+	LastModified string
+}
+
+// RobotsContent godoc
+// @Description What was detected in the fetched robots.txt file. 'detected' is one of: robots.txt, html, json, binary. 'policy' is applied to invalid files: allow_all, disallow_all or parse
+// @Type RobotsContent
+type RobotsContent struct {
+	ContentType     string `json:"content_type"`
+	ContentEncoding string `json:"content_encoding,omitempty"`
+	Charset         string `json:"charset"`
+	Bom             bool   `json:"bom"`
+	Detected        string `json:"detected"`
+	Valid           bool   `json:"valid"`
+	Policy          string `json:"policy,omitempty"`
+}
+
+// TokenVerdict godoc
+// @Description Verdict for one product token of the crawl purpose
+// @Type TokenVerdict
+type TokenVerdict struct {
+	Token     string `json:"token"`
+	IsAllowed bool   `json:"is_allowed"`
+}
+
+// AiTxtVerdict godoc
+// @Description AI-usage opt-out verdict based on the ai.txt file. Returned only for the 'ai-training' purpose
+// @Type AiTxtVerdict
+type AiTxtVerdict struct {
+	IsAllowed  bool   `json:"is_allowed"`
+	Found      bool   `json:"found"`
+	StatusCode int    `json:"status_code"`
+	Error      string `json:"error,omitempty"`
+	// ErrorCode is one of the ErrorCode constants, it is set when the ai.txt file can not be fetched or the
+	// status is not 2xx or 4xx
+	ErrorCode string `json:"error_code,omitempty"`
 }
 
 type TargetResponse struct {
 	StatusCode int
+	Header     http.Header
 	Body       []byte
+	Content    *RobotsContent
+	// ContentEncoding is the Content-Encoding of the response. The body is already decompressed
+	ContentEncoding string
+	Url             string
+	Source          string
+	FetchedAt       time.Time
+	ExpiresAt       time.Time
+}
+
+// RuleComparisonRequest godoc
+// @Description Sample URLs and user agents to evaluate against the custom rule and the live robots.txt
+// @Type RuleComparisonRequest
+type RuleComparisonRequest struct {
+	Urls       []string `json:"urls"`
+	UserAgents []string `json:"user_agents"`
+}
+
+// RuleComparisonResponse godoc
+// @Description Side-by-side comparison of a custom rule and the live robots.txt of its domain. 'live_policy' is the InvalidRobotsTxt policy applied to the live file if it is an html page, json or binary data
+// @Type RuleComparisonResponse
+type RuleComparisonResponse struct {
+	RuleID            int              `json:"rule_id"`
+	Domain            string           `json:"domain"`
+	Blocked           bool             `json:"blocked"`
+	LiveStatusCode    int              `json:"live_status_code"`
+	LivePolicy        string           `json:"live_policy,omitempty"`
+	Verdicts          []VerdictCompare `json:"verdicts"`
+	IdenticalVerdicts bool             `json:"identical_verdicts"`
+	Diff              string           `json:"diff"`
+	DiffTooLarge      bool             `json:"diff_too_large,omitempty"`
+}
+
+// VerdictCompare godoc
+// @Description Crawl verdicts of the custom rule and the live robots.txt for one URL and user agent
+// @Type VerdictCompare
+type VerdictCompare struct {
+	Url         string `json:"url"`
+	UserAgent   string `json:"user_agent"`
+	CustomRule  bool   `json:"custom_rule_allowed"`
+	LiveRobots  bool   `json:"live_allowed"`
+	IsDifferent bool   `json:"is_different"`
+}
+
+// TdmReservationResponse godoc
+// @Description Text and Data Mining reservation (TDMRep) for the URL
+// @Type TdmReservationResponse
+type TdmReservationResponse struct {
+	Url         string `json:"url"`
+	Reservation bool   `json:"reservation"`
+	Policy      string `json:"policy,omitempty"`
+	Source      string `json:"source"`
+	Location    string `json:"location,omitempty"`
+	StatusCode  int    `json:"status_code"`
+	Error       string `json:"error"`
+	// ErrorCode is one of the ErrorCode constants, it is set when the page can not be fetched
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// TdmRepFile is the cached tdmrep.json file of an origin. The missing file has no rules, Error is the parse error of
+// the invalid file
+type TdmRepFile struct {
+	StatusCode int          `json:"status_code"`
+	Rules      []TdmRepRule `json:"rules,omitempty"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// TdmRepRule is a single entry of the /.well-known/tdmrep.json file
+type TdmRepRule struct {
+	Location    string `json:"location"`
+	Reservation int    `json:"tdm-reservation"`
+	Policy      string `json:"tdm-policy"`
+}
+
+// PageDirectivesResponse godoc
+// @Description Page-level robots directives from the X-Robots-Tag headers and the robots meta tags
+// @Type PageDirectivesResponse
+type PageDirectivesResponse struct {
+	Url        string   `json:"url"`
+	UserAgent  string   `json:"user_agent"`
+	Directives []string `json:"directives"`
+	NoIndex    bool     `json:"noindex"`
+	NoFollow   bool     `json:"nofollow"`
+	NoAi       bool     `json:"noai"`
+	NoImageAi  bool     `json:"noimageai"`
+	StatusCode int      `json:"status_code"`
+	Error      string   `json:"error"`
+	// ErrorCode is one of the ErrorCode constants, it is set when the page can not be fetched
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// PageDirectives is the cached set of directives of a page for all user agents
+type PageDirectives struct {
+	StatusCode int              `json:"status_code"`
+	Groups     []DirectiveGroup `json:"groups"`
+}
+
+// DirectiveGroup is a set of directives from one header or meta tag. Empty UserAgent applies to all crawlers
+type DirectiveGroup struct {
+	UserAgent  string   `json:"user_agent"`
+	Source     string   `json:"source"`
+	Directives []string `json:"directives"`
 }
@@ -0,0 +1,358 @@
+package persistence
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	"github.com/IliaW/rule-api/internal/model"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ErrLeaseLost is returned when the job is updated by the worker that no longer holds its lease, e.g. the job was
+// taken over by another worker after a long pause.
+var ErrLeaseLost = errors.New("is claimed by another worker")
+
+// maxJobErrorLength is the size of the error column
+const maxJobErrorLength = 1000
+
+//go:generate go run github.com/vektra/mockery/v2@v2.53.0 --name JobStorage
+type JobStorage interface {
+	// CreateJob saves the job with its url list. The job is saved only if the whole list is saved. Total is set to
+	// the number of the urls.
+	CreateJob(job *model.Job, urls iter.Seq2[string, error]) error
+	GetJob(string) (*model.Job, error)
+	// GetJobUrls returns at most limit urls of the job list starting from the offset.
+	GetJobUrls(id string, offset int, limit int) ([]string, error)
+	// WriteJobResult writes the NDJSON result of the job in the order of the url list.
+	WriteJobResult(id string, w io.Writer) error
+	// ClaimJob marks the oldest queued job, or a running job without progress for staleAfter, as running.
+	// The job gets a new lease. The other methods change the job only with this lease and return ErrLeaseLost
+	// otherwise. Returns nil if there is no job to run.
+	ClaimJob(staleAfter time.Duration) (*model.Job, error)
+	// UpdateJobProgress saves the progress of the job together with the NDJSON result of the chunk of the urls
+	// starting from chunkStart.
+	UpdateJobProgress(job *model.Job, chunkStart int, result []byte) error
+	// HeartbeatJob marks the running job as alive, so it is not taken over while a chunk is checked.
+	HeartbeatJob(id string, lease string) error
+	// FinishJob sets the final status of the job and removes its url list.
+	FinishJob(id string, lease string, status string, errMessage string) error
+	RequeueJob(id string, lease string) error
+	// DeleteFinishedJobs deletes the jobs finished more than olderThan ago with their results. Returns the number of
+	// the deleted jobs.
+	DeleteFinishedJobs(olderThan time.Duration) (int64, error)
+}
+
+const jobColumns = `id, status, user_agent, purpose, total, processed, allowed, disallowed, failed, error,
+					created_at, started_at, finished_at`
+
+type JobRepository struct {
+	db *sql.DB
+}
+
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{
+		db: db,
+	}
+}
+
+func (r *JobRepository) CreateJob(job *model.Job, urls iter.Seq2[string, error]) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			rollback(tx)
+		}
+	}()
+	err = tx.QueryRow(`INSERT INTO web_crawler.crawl_job (id, status, user_agent, purpose)
+								VALUES ($1, $2, $3, $4) RETURNING created_at`,
+		job.ID, model.JobStatusQueued, job.UserAgent, job.Purpose).Scan(&job.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("job with id '%s' %w", job.ID, ErrDuplicate)
+		}
+		return err
+	}
+	if job.Total, err = copyJobUrls(tx, job.ID, urls); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(`UPDATE web_crawler.crawl_job SET total = $1 WHERE id = $2`, job.Total, job.ID); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	job.Status = model.JobStatusQueued
+	slog.Debug("job saved to db.")
+
+	return nil
+}
+
+// copyJobUrls saves the urls with COPY, since the list may have millions of urls. Returns the number of the urls.
+func copyJobUrls(tx *sql.Tx, id string, urls iter.Seq2[string, error]) (int, error) {
+	stmt, err := tx.Prepare(pq.CopyInSchema("web_crawler", "crawl_job_url", "job_id", "position", "url"))
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for url, err := range urls {
+		if err != nil {
+			_ = stmt.Close()
+			return 0, err
+		}
+		if _, err = stmt.Exec(id, total, url); err != nil {
+			_ = stmt.Close()
+			return 0, err
+		}
+		total++
+	}
+	if _, err = stmt.Exec(); err != nil {
+		_ = stmt.Close()
+		return 0, err
+	}
+
+	return total, stmt.Close()
+}
+
+func (r *JobRepository) GetJob(id string) (*model.Job, error) {
+	job, err := scanJob(r.db.QueryRow(`SELECT `+jobColumns+`
+								FROM web_crawler.crawl_job
+								WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("job with id '%s' %w", id, ErrNotFound)
+		}
+		slog.Debug("failed to get job from database.", slog.String("err", err.Error()))
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (r *JobRepository) GetJobUrls(id string, offset int, limit int) ([]string, error) {
+	rows, err := r.db.Query(`SELECT url
+								FROM web_crawler.crawl_job_url
+								WHERE job_id = $1 AND position >= $2
+								ORDER BY position
+								LIMIT $3`, id, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows(rows)
+	urls := make([]string, 0, limit)
+	for rows.Next() {
+		var url string
+		if err = rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+
+	return urls, rows.Err()
+}
+
+func (r *JobRepository) WriteJobResult(id string, w io.Writer) error {
+	rows, err := r.db.Query(`SELECT result
+								FROM web_crawler.crawl_job_result
+								WHERE job_id = $1
+								ORDER BY chunk_start`, id)
+	if err != nil {
+		return err
+	}
+	defer closeRows(rows)
+	for rows.Next() {
+		var result []byte
+		if err = rows.Scan(&result); err != nil {
+			return err
+		}
+		if _, err = w.Write(result); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *JobRepository) ClaimJob(staleAfter time.Duration) (*model.Job, error) {
+	lease := uuid.NewString()
+	// SKIP LOCKED lets several workers claim the jobs without waiting for each other
+	job, err := scanJob(r.db.QueryRow(`UPDATE web_crawler.crawl_job
+								SET status = $1, claimed_by = $4, started_at = COALESCE(started_at, CURRENT_TIMESTAMP),
+									updated_at = CURRENT_TIMESTAMP
+								WHERE id = (SELECT id FROM web_crawler.crawl_job
+											WHERE status = $2 OR (status = $1
+												AND updated_at < CURRENT_TIMESTAMP - $3 * INTERVAL '1 millisecond')
+											ORDER BY created_at
+											LIMIT 1
+											FOR UPDATE SKIP LOCKED)
+								RETURNING `+jobColumns,
+		model.JobStatusRunning, model.JobStatusQueued, staleAfter.Milliseconds(), lease))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	job.Lease = lease
+	slog.Debug("job claimed.", slog.String("id", job.ID))
+
+	return job, nil
+}
+
+func (r *JobRepository) UpdateJobProgress(job *model.Job, chunkStart int, result []byte) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			rollback(tx)
+		}
+	}()
+	res, err := tx.Exec(`UPDATE web_crawler.crawl_job
+								SET processed = $1, allowed = $2, disallowed = $3, failed = $4,
+									updated_at = CURRENT_TIMESTAMP
+								WHERE id = $5 AND claimed_by = $6`,
+		job.Processed, job.Allowed, job.Disallowed, job.Failed, job.ID, job.Lease)
+	if err != nil {
+		return err
+	}
+	if err = checkLease(res, job.ID); err != nil {
+		return err
+	}
+	// the chunk of an interrupted run is replaced, since the job is resumed from the last saved chunk
+	_, err = tx.Exec(`INSERT INTO web_crawler.crawl_job_result (job_id, chunk_start, result)
+								VALUES ($1, $2, $3)
+								ON CONFLICT (job_id, chunk_start) DO UPDATE SET result = EXCLUDED.result`,
+		job.ID, chunkStart, string(result))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *JobRepository) HeartbeatJob(id string, lease string) error {
+	result, err := r.db.Exec(`UPDATE web_crawler.crawl_job
+								SET updated_at = CURRENT_TIMESTAMP
+								WHERE id = $1 AND claimed_by = $2`, id, lease)
+	if err != nil {
+		return err
+	}
+
+	return checkLease(result, id)
+}
+
+func (r *JobRepository) FinishJob(id string, lease string, status string, errMessage string) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			rollback(tx)
+		}
+	}()
+	result, err := tx.Exec(`UPDATE web_crawler.crawl_job
+								SET status = $1, error = $2, claimed_by = '', finished_at = CURRENT_TIMESTAMP,
+									updated_at = CURRENT_TIMESTAMP
+								WHERE id = $3 AND claimed_by = $4`,
+		status, truncate(errMessage, maxJobErrorLength), id, lease)
+	if err != nil {
+		return err
+	}
+	if err = checkLease(result, id); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(`DELETE FROM web_crawler.crawl_job_url WHERE job_id = $1`, id); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	slog.Debug("job finished.", slog.String("id", id), slog.String("status", status))
+
+	return nil
+}
+
+func (r *JobRepository) RequeueJob(id string, lease string) error {
+	result, err := r.db.Exec(`UPDATE web_crawler.crawl_job
+								SET status = $1, claimed_by = '', updated_at = CURRENT_TIMESTAMP
+								WHERE id = $2 AND claimed_by = $3`, model.JobStatusQueued, id, lease)
+	if err != nil {
+		return err
+	}
+
+	return checkLease(result, id)
+}
+
+func (r *JobRepository) DeleteFinishedJobs(olderThan time.Duration) (int64, error) {
+	// the results are deleted by the foreign key cascade
+	result, err := r.db.Exec(`DELETE FROM web_crawler.crawl_job
+								WHERE status IN ($1, $2)
+									AND finished_at < CURRENT_TIMESTAMP - $3 * INTERVAL '1 millisecond'`,
+		model.JobStatusCompleted, model.JobStatusFailed, olderThan.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// checkLease returns ErrLeaseLost if the job is not updated, since the job is claimed with another lease.
+func checkLease(result sql.Result, id string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("job with id '%s' %w", id, ErrLeaseLost)
+	}
+	return nil
+}
+
+func rollback(tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil {
+		slog.Error("failed to rollback transaction.", slog.String("err", err.Error()))
+	}
+}
+
+func closeRows(rows *sql.Rows) {
+	if err := rows.Close(); err != nil {
+		slog.Error("failed to close rows.", slog.String("err", err.Error()))
+	}
+}
+
+// truncate cuts the string to maxLength characters.
+func truncate(s string, maxLength int) string {
+	if utf8.RuneCountInString(s) <= maxLength {
+		return s
+	}
+	return string([]rune(s)[:maxLength])
+}
+
+func scanJob(row *sql.Row) (*model.Job, error) {
+	var job model.Job
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(&job.ID, &job.Status, &job.UserAgent, &job.Purpose, &job.Total, &job.Processed, &job.Allowed,
+		&job.Disallowed, &job.Failed, &job.Error, &job.CreatedAt, &startedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+
+	return &job, nil
+}
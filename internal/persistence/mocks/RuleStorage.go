@@ -30,6 +30,114 @@ func (_m *RuleStorage) Delete(_a0 string) error {
 	return r0
 }
 
+// DeleteAlias provides a mock function with given fields: _a0
+func (_m *RuleStorage) DeleteAlias(_a0 string) error {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAlias")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAlias provides a mock function with given fields: _a0
+func (_m *RuleStorage) GetAlias(_a0 string) (*model.DomainAlias, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAlias")
+	}
+
+	var r0 *model.DomainAlias
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.DomainAlias, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.DomainAlias); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.DomainAlias)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAliases provides a mock function with no fields
+func (_m *RuleStorage) GetAliases() ([]*model.DomainAlias, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAliases")
+	}
+
+	var r0 []*model.DomainAlias
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*model.DomainAlias, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*model.DomainAlias); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.DomainAlias)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByDomain provides a mock function with given fields: _a0
+func (_m *RuleStorage) GetByDomain(_a0 string) (*model.Rule, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByDomain")
+	}
+
+	var r0 *model.Rule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.Rule, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.Rule); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Rule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetById provides a mock function with given fields: _a0
 func (_m *RuleStorage) GetById(_a0 string) (*model.Rule, error) {
 	ret := _m.Called(_a0)
@@ -90,6 +198,36 @@ func (_m *RuleStorage) GetByUrl(_a0 string) (*model.Rule, error) {
 	return r0, r1
 }
 
+// List provides a mock function with given fields: _a0
+func (_m *RuleStorage) List(_a0 *model.RuleFilter) ([]*model.Rule, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*model.Rule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*model.RuleFilter) ([]*model.Rule, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(*model.RuleFilter) []*model.Rule); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Rule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.RuleFilter) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: _a0
 func (_m *RuleStorage) Save(_a0 *model.Rule) (int64, error) {
 	ret := _m.Called(_a0)
@@ -118,6 +256,34 @@ func (_m *RuleStorage) Save(_a0 *model.Rule) (int64, error) {
 	return r0, r1
 }
 
+// SaveAlias provides a mock function with given fields: _a0
+func (_m *RuleStorage) SaveAlias(_a0 *model.DomainAlias) (int64, error) {
+	ret := _m.Called(_a0)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAlias")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*model.DomainAlias) (int64, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(*model.DomainAlias) int64); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.DomainAlias) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: _a0
 func (_m *RuleStorage) Update(_a0 *model.Rule) (*model.Rule, error) {
 	ret := _m.Called(_a0)
@@ -6,19 +6,36 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/IliaW/rule-api/internal/model"
 	"github.com/IliaW/rule-api/util"
+	"github.com/lib/pq"
 )
 
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrDuplicate     = errors.New("already exists")
+	ErrInvalidDomain = errors.New("invalid domain")
+)
+
+// uniqueViolation is the postgres error code of the unique constraint violation
+const uniqueViolation = "23505"
+
 //go:generate go run github.com/vektra/mockery/v2@v2.53.0 --name RuleStorage
 type RuleStorage interface {
 	GetByUrl(string) (*model.Rule, error)
+	GetByDomain(string) (*model.Rule, error)
 	GetById(string) (*model.Rule, error)
+	List(*model.RuleFilter) ([]*model.Rule, error)
 	Save(*model.Rule) (int64, error)
 	Update(*model.Rule) (*model.Rule, error)
 	Delete(string) error
+	GetAlias(string) (*model.DomainAlias, error)
+	GetAliases() ([]*model.DomainAlias, error)
+	SaveAlias(*model.DomainAlias) (int64, error)
+	DeleteAlias(string) error
 }
 
 type RuleRepository struct {
@@ -35,16 +52,21 @@ func NewRuleRepository(db *sql.DB) *RuleRepository {
 func (r *RuleRepository) GetByUrl(url string) (*model.Rule, error) {
 	domain, err := util.GetDomain(url)
 	if err != nil {
-		return nil, errors.New(fmt.Sprintf("failed to parse url. %s", err.Error()))
+		return nil, fmt.Errorf("%w. failed to parse url. %s", ErrInvalidDomain, err.Error())
 	}
+
+	return r.GetByDomain(domain)
+}
+
+func (r *RuleRepository) GetByDomain(domain string) (*model.Rule, error) {
 	var rule model.Rule
 	row := r.db.QueryRow(`SELECT id, domain, blocked, robots_txt, created_at, updated_at 
 								FROM web_crawler.custom_rule 
 								WHERE domain = $1`, domain)
-	err = row.Scan(&rule.ID, &rule.Domain, &rule.Blocked, &rule.RobotsTxt, &rule.CreatedAt, &rule.UpdatedAt)
+	err := row.Scan(&rule.ID, &rule.Domain, &rule.Blocked, &rule.RobotsTxt, &rule.CreatedAt, &rule.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New(fmt.Sprintf("rule with domain '%s' not found", domain))
+			return nil, fmt.Errorf("rule with domain '%s' %w", domain, ErrNotFound)
 		}
 		slog.Debug("failed to get rule from database.", slog.String("err", err.Error()))
 		return nil, err
@@ -62,7 +84,7 @@ func (r *RuleRepository) GetById(id string) (*model.Rule, error) {
 	err := row.Scan(&rule.ID, &rule.Domain, &rule.Blocked, &rule.RobotsTxt, &rule.CreatedAt, &rule.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New(fmt.Sprintf("rule with id '%s' not found", id))
+			return nil, fmt.Errorf("rule with id '%s' %w", id, ErrNotFound)
 		}
 		slog.Debug("failed to get rule from database.", slog.String("err", err.Error()))
 		return nil, err
@@ -72,13 +94,103 @@ func (r *RuleRepository) GetById(id string) (*model.Rule, error) {
 	return &rule, nil
 }
 
+// List returns up to filter.Limit rules in the order of filter.SortBy. The pagination uses the sort key and the id
+// of the last rule of the previous page, so the pages are stable while the rules are added.
+func (r *RuleRepository) List(filter *model.RuleFilter) ([]*model.Rule, error) {
+	var conditions []string
+	var args []any
+	arg := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.Domain != "" {
+		conditions = append(conditions, "domain ILIKE "+arg("%"+escapeLike(filter.Domain)+"%"))
+	}
+	if filter.RobotsTxt != "" {
+		conditions = append(conditions, "robots_txt ILIKE "+arg("%"+escapeLike(filter.RobotsTxt)+"%"))
+	}
+	if filter.Blocked != nil {
+		conditions = append(conditions, "blocked = "+arg(*filter.Blocked))
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, "created_at <= "+arg(*filter.CreatedTo))
+	}
+	if filter.UpdatedFrom != nil {
+		conditions = append(conditions, "updated_at >= "+arg(*filter.UpdatedFrom))
+	}
+	if filter.UpdatedTo != nil {
+		conditions = append(conditions, "updated_at <= "+arg(*filter.UpdatedTo))
+	}
+
+	column := "updated_at"
+	if filter.SortBy == model.RuleSortDomain {
+		column = "domain"
+	}
+	direction, comparison := "ASC", ">"
+	if filter.Order == model.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+	if filter.After != nil {
+		var key any = filter.After.UpdatedAt
+		if column == "domain" {
+			key = filter.After.Domain
+		}
+		conditions = append(conditions,
+			fmt.Sprintf("(%s, id) %s (%s, %s)", column, comparison, arg(key), arg(filter.After.ID)))
+	}
+
+	query := `SELECT id, domain, blocked, robots_txt, created_at, updated_at 
+				FROM web_crawler.custom_rule`
+	if len(conditions) != 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT %s", column, direction, direction, arg(filter.Limit))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows.", slog.String("err", err.Error()))
+		}
+	}()
+
+	rules := make([]*model.Rule, 0)
+	for rows.Next() {
+		var rule model.Rule
+		err = rows.Scan(&rule.ID, &rule.Domain, &rule.Blocked, &rule.RobotsTxt, &rule.CreatedAt, &rule.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, &rule)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	slog.Debug("rules fetched from db.")
+
+	return rules, nil
+}
+
 func (r *RuleRepository) Save(rule *model.Rule) (int64, error) {
+	domain, err := util.CanonicalHost(rule.Domain)
+	if err != nil {
+		return 0, fmt.Errorf("%w. %s", ErrInvalidDomain, err.Error())
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	var id int64
-	err := r.db.QueryRow(`INSERT INTO web_crawler.custom_rule (domain, blocked, robots_txt) 
-								VALUES ($1, $2, $3) RETURNING id`, rule.Domain, rule.Blocked, rule.RobotsTxt).Scan(&id)
+	err = r.db.QueryRow(`INSERT INTO web_crawler.custom_rule (domain, blocked, robots_txt) 
+								VALUES ($1, $2, $3) RETURNING id`, domain, rule.Blocked, rule.RobotsTxt).Scan(&id)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, fmt.Errorf("rule with domain '%s' %w", domain, ErrDuplicate)
+		}
 		return 0, err
 	}
 	slog.Debug("rule saved to db.")
@@ -87,10 +199,20 @@ func (r *RuleRepository) Save(rule *model.Rule) (int64, error) {
 }
 
 func (r *RuleRepository) Update(rule *model.Rule) (*model.Rule, error) {
-	_, err := r.db.Exec(`UPDATE web_crawler.custom_rule 
+	domain, err := util.CanonicalHost(rule.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("%w. %s", ErrInvalidDomain, err.Error())
+	}
+	result, err := r.db.Exec(`UPDATE web_crawler.custom_rule 
 								SET domain = $1, blocked = $2, robots_txt = $3 
-								WHERE id = $4`, rule.Domain, rule.Blocked, rule.RobotsTxt, rule.ID)
+								WHERE id = $4`, domain, rule.Blocked, rule.RobotsTxt, rule.ID)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("rule with domain '%s' %w", domain, ErrDuplicate)
+		}
+		return nil, err
+	}
+	if err = checkAffected(result, fmt.Sprintf("rule with id '%d'", rule.ID)); err != nil {
 		return nil, err
 	}
 	slog.Debug("rule updated in db.")
@@ -99,11 +221,120 @@ func (r *RuleRepository) Update(rule *model.Rule) (*model.Rule, error) {
 }
 
 func (r *RuleRepository) Delete(ruleId string) error {
-	_, err := r.db.Exec("DELETE FROM web_crawler.custom_rule WHERE id = $1", ruleId)
+	result, err := r.db.Exec("DELETE FROM web_crawler.custom_rule WHERE id = $1", ruleId)
 	if err != nil {
 		return err
 	}
+	if err = checkAffected(result, fmt.Sprintf("rule with id '%s'", ruleId)); err != nil {
+		return err
+	}
 	slog.Debug("rule deleted from db.")
 
 	return nil
 }
+
+func (r *RuleRepository) GetAlias(alias string) (*model.DomainAlias, error) {
+	var domainAlias model.DomainAlias
+	row := r.db.QueryRow(`SELECT id, alias, domain, created_at 
+								FROM web_crawler.domain_alias 
+								WHERE alias = $1`, alias)
+	err := row.Scan(&domainAlias.ID, &domainAlias.Alias, &domainAlias.Domain, &domainAlias.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("alias '%s' %w", alias, ErrNotFound)
+		}
+		slog.Debug("failed to get alias from database.", slog.String("err", err.Error()))
+		return nil, err
+	}
+	slog.Debug("alias fetched from db.")
+
+	return &domainAlias, nil
+}
+
+func (r *RuleRepository) GetAliases() ([]*model.DomainAlias, error) {
+	rows, err := r.db.Query(`SELECT id, alias, domain, created_at 
+								FROM web_crawler.domain_alias 
+								ORDER BY alias`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			slog.Error("failed to close rows.", slog.String("err", err.Error()))
+		}
+	}()
+
+	aliases := make([]*model.DomainAlias, 0)
+	for rows.Next() {
+		var domainAlias model.DomainAlias
+		err = rows.Scan(&domainAlias.ID, &domainAlias.Alias, &domainAlias.Domain, &domainAlias.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		aliases = append(aliases, &domainAlias)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	slog.Debug("aliases fetched from db.")
+
+	return aliases, nil
+}
+
+func (r *RuleRepository) SaveAlias(domainAlias *model.DomainAlias) (int64, error) {
+	alias, err := util.CanonicalHost(domainAlias.Alias)
+	if err != nil {
+		return 0, fmt.Errorf("%w. %s", ErrInvalidDomain, err.Error())
+	}
+	domain, err := util.CanonicalHost(domainAlias.Domain)
+	if err != nil {
+		return 0, fmt.Errorf("%w. %s", ErrInvalidDomain, err.Error())
+	}
+	var id int64
+	err = r.db.QueryRow(`INSERT INTO web_crawler.domain_alias (alias, domain) 
+								VALUES ($1, $2) RETURNING id`, alias, domain).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, fmt.Errorf("alias '%s' %w", alias, ErrDuplicate)
+		}
+		return 0, err
+	}
+	slog.Debug("alias saved to db.")
+
+	return id, nil
+}
+
+func (r *RuleRepository) DeleteAlias(aliasId string) error {
+	result, err := r.db.Exec("DELETE FROM web_crawler.domain_alias WHERE id = $1", aliasId)
+	if err != nil {
+		return err
+	}
+	if err = checkAffected(result, fmt.Sprintf("alias with id '%s'", aliasId)); err != nil {
+		return err
+	}
+	slog.Debug("alias deleted from db.")
+
+	return nil
+}
+
+// escapeLike escapes the wildcards of the LIKE pattern, so the value is matched literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+// checkAffected returns ErrNotFound if no rows are affected by the statement.
+func checkAffected(result sql.Result, entity string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s %w", entity, ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	u "net/url"
+	"time"
+
+	"github.com/IliaW/rule-api/util"
+	xproxy "golang.org/x/net/proxy"
+)
+
+type DialFunc = func(ctx context.Context, network string, address string) (net.Conn, error)
+
+// ResolveFunc returns the checked addresses of the target host. The tunnel is opened to them instead of the host.
+// Nil addresses mean the host is passed to the proxy as is.
+type ResolveFunc = func(ctx context.Context, host string) ([]netip.Addr, error)
+
+// DialContext returns the DialContext function of the http transport. The connections to the targets go through the
+// proxy selected for the host, or are made by direct if there is no proxy. The proxies are connected by proxyDial and
+// the tunnel is made with CONNECT for http and https proxies and with SOCKS5 for socks5 proxies. If resolve is not nil,
+// the tunnel is opened to the resolved address, so the proxy does not resolve the host again.
+func (s *Selector) DialContext(direct DialFunc, proxyDial DialFunc, resolve ResolveFunc) DialFunc {
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		proxyUrl := s.Select(host)
+		if proxyUrl == nil {
+			return direct(ctx, network, address)
+		}
+
+		targets := []string{address}
+		if resolve != nil {
+			addrs, err := resolve(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			if addrs != nil {
+				targets = targets[:0]
+				for _, addr := range addrs {
+					targets = append(targets, net.JoinHostPort(addr.String(), port))
+				}
+			}
+		}
+		var errs []error
+		for _, target := range targets {
+			conn, err := tunnel(ctx, proxyDial, proxyUrl, network, target)
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		return nil, errors.Join(errs...)
+	}
+}
+
+// tunnel connects to the target through the proxy.
+func tunnel(ctx context.Context, proxyDial DialFunc, proxyUrl *u.URL, network string, target string) (net.Conn,
+	error) {
+	if proxyUrl.Scheme == "socks5" || proxyUrl.Scheme == "socks5h" {
+		var auth *xproxy.Auth
+		if proxyUrl.User != nil {
+			password, _ := proxyUrl.User.Password()
+			auth = &xproxy.Auth{User: proxyUrl.User.Username(), Password: password}
+		}
+		dialer, err := xproxy.SOCKS5("tcp", proxyAddress(proxyUrl), auth, dialFunc(proxyDial))
+		if err != nil {
+			return nil, err
+		}
+		return dialer.(xproxy.ContextDialer).DialContext(ctx, network, target)
+	}
+
+	conn, err := proxyDial(ctx, "tcp", proxyAddress(proxyUrl))
+	if err != nil {
+		return nil, err
+	}
+	if proxyUrl.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxyUrl.Hostname()})
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	if err = connect(ctx, conn, proxyUrl, target); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// connect asks the http proxy to open the tunnel to the target.
+func connect(ctx context.Context, conn net.Conn, proxyUrl *u.URL, target string) error {
+	// the deadline interrupts the exchange when the context is done
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &u.URL{Opaque: target},
+		Host:   target,
+		Header: make(http.Header),
+	}
+	if proxyUrl.User != nil {
+		password, _ := proxyUrl.User.Password()
+		credentials := proxyUrl.User.Username() + ":" + password
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+	if err := req.Write(conn); err != nil {
+		return contextError(ctx, err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return contextError(ctx, err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxy %s refused the connection to %s: %s", Redact(proxyUrl), target, resp.Status)
+	}
+
+	return nil
+}
+
+func contextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// proxyAddress returns the host and port of the proxy. The default ports are the same as in the http transport.
+func proxyAddress(proxyUrl *u.URL) string {
+	if proxyUrl.Port() != "" {
+		return proxyUrl.Host
+	}
+	port := map[string]string{"http": "80", "https": "443", "socks5": "1080", "socks5h": "1080"}[proxyUrl.Scheme]
+	host := proxyUrl.Hostname()
+	if canonical, err := util.CanonicalHost(host); err == nil {
+		host = canonical
+	}
+
+	return net.JoinHostPort(host, port)
+}
+
+// dialFunc adapts the dial function to the dialer of the SOCKS5 client.
+type dialFunc DialFunc
+
+func (f dialFunc) Dial(network string, address string) (net.Conn, error) {
+	return f(context.Background(), network, address)
+}
+
+func (f dialFunc) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	return f(ctx, network, address)
+}
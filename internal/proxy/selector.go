@@ -0,0 +1,158 @@
+package proxy
+
+import (
+	"fmt"
+	"net/netip"
+	u "net/url"
+	"path"
+	"strings"
+
+	"github.com/IliaW/rule-api/config"
+	"github.com/IliaW/rule-api/util"
+)
+
+const Direct = "direct"
+
+// Selector picks the outbound proxy for a request. The first matching override wins, hosts from the no-proxy list are
+// requested directly and the default proxy is used for the rest. Supported schemes: http, https, socks5 and socks5h.
+type Selector struct {
+	defaultProxy *u.URL
+	overrides    []override
+	noProxy      []matcher
+	onSelect     func(host string, proxy string)
+}
+
+type override struct {
+	pattern matcher
+	proxy   *u.URL
+}
+
+// matcher matches a host by a glob pattern ('*.de'), a domain with its subdomains ('example.com') or a CIDR.
+type matcher struct {
+	pattern string
+	prefix  netip.Prefix
+}
+
+// NewSelector creates a selector. onSelect is called with the host and the redacted proxy, or Direct, for every
+// new connection and may be nil.
+func NewSelector(cfg *config.ProxyConfig, onSelect func(host string, proxy string)) (*Selector, error) {
+	s := &Selector{onSelect: onSelect}
+	if cfg == nil {
+		return s, nil
+	}
+	var err error
+	if cfg.Url != "" {
+		if s.defaultProxy, err = parseProxyUrl(cfg.Url, cfg.Username, cfg.Password); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range cfg.Overrides {
+		username, password := o.Username, o.Password
+		if username == "" {
+			username, password = cfg.Username, cfg.Password
+		}
+		proxyUrl, err := parseProxyUrl(o.Url, username, password)
+		if err != nil {
+			return nil, err
+		}
+		pattern, err := newMatcher(o.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		s.overrides = append(s.overrides, override{pattern: pattern, proxy: proxyUrl})
+	}
+	for _, p := range cfg.NoProxy {
+		m, err := newMatcher(p)
+		if err != nil {
+			return nil, err
+		}
+		s.noProxy = append(s.noProxy, m)
+	}
+
+	return s, nil
+}
+
+// Select returns the proxy for the target host, or nil if the host is requested directly.
+func (s *Selector) Select(host string) *u.URL {
+	if canonical, err := util.CanonicalHost(host); err == nil {
+		host = canonical
+	}
+	proxyUrl := s.find(host)
+	if s.onSelect != nil {
+		s.onSelect(host, Redact(proxyUrl))
+	}
+
+	return proxyUrl
+}
+
+func (s *Selector) find(host string) *u.URL {
+	for _, o := range s.overrides {
+		if o.pattern.match(host) {
+			return o.proxy
+		}
+	}
+	for _, m := range s.noProxy {
+		if m.match(host) {
+			return nil
+		}
+	}
+
+	return s.defaultProxy
+}
+
+// Redact returns the proxy url without credentials, or Direct if there is no proxy.
+func Redact(proxyUrl *u.URL) string {
+	if proxyUrl == nil {
+		return Direct
+	}
+	return proxyUrl.Scheme + "://" + proxyUrl.Host
+}
+
+func parseProxyUrl(rawUrl string, username string, password string) (*u.URL, error) {
+	proxyUrl, err := u.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url. %s", err.Error())
+	}
+	switch proxyUrl.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme '%s'", proxyUrl.Scheme)
+	}
+	if proxyUrl.Hostname() == "" {
+		return nil, fmt.Errorf("invalid proxy url '%s'. Url should contain hostname", Redact(proxyUrl))
+	}
+	if username != "" && proxyUrl.User == nil {
+		proxyUrl.User = u.UserPassword(username, password)
+	}
+
+	return proxyUrl, nil
+}
+
+func newMatcher(pattern string) (matcher, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if strings.Contains(pattern, "/") {
+		prefix, err := netip.ParsePrefix(pattern)
+		if err != nil {
+			return matcher{}, fmt.Errorf("invalid proxy pattern '%s'. %s", pattern, err.Error())
+		}
+		return matcher{prefix: prefix.Masked()}, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return matcher{}, fmt.Errorf("invalid proxy pattern '%s'. %s", pattern, err.Error())
+	}
+
+	return matcher{pattern: strings.TrimPrefix(pattern, ".")}, nil
+}
+
+func (m matcher) match(host string) bool {
+	if m.prefix.IsValid() {
+		addr, err := netip.ParseAddr(host)
+		return err == nil && m.prefix.Contains(addr.Unmap())
+	}
+	if strings.ContainsAny(m.pattern, "*?[") {
+		matched, _ := path.Match(m.pattern, host)
+		return matched
+	}
+
+	return host == m.pattern || strings.HasSuffix(host, "."+m.pattern)
+}
@@ -0,0 +1,134 @@
+package psl
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/IliaW/rule-api/util"
+)
+
+// The list is updated with the 'cmd/psl-update' command.
+//
+//go:embed public_suffix_list.dat
+var embeddedList []byte
+
+var (
+	defaultList *List
+	loadOnce    sync.Once
+)
+
+// List is a parsed Public Suffix List (https://publicsuffix.org). Rules of the ICANN and the PRIVATE sections are
+// used, so 'foo.github.io' and 'bar.github.io' are different registrable domains.
+type List struct {
+	rules      map[string]struct{}
+	wildcards  map[string]struct{}
+	exceptions map[string]struct{}
+}
+
+// Parse reads the list in the publicsuffix.org format. Rules are converted to punycode.
+func Parse(r io.Reader) (*List, error) {
+	list := &List{
+		rules:      make(map[string]struct{}),
+		wildcards:  make(map[string]struct{}),
+		exceptions: make(map[string]struct{}),
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		rule := strings.Fields(line)[0]
+		target := list.rules
+		switch {
+		case strings.HasPrefix(rule, "!"):
+			rule, target = rule[1:], list.exceptions
+		case strings.HasPrefix(rule, "*."):
+			rule, target = rule[2:], list.wildcards
+		}
+		canonical, err := util.CanonicalHost(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule '%s'. %s", line, err.Error())
+		}
+		target[canonical] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(list.rules)+len(list.wildcards) == 0 {
+		return nil, errors.New("public suffix list has no rules")
+	}
+
+	return list, nil
+}
+
+// Size returns the number of rules in the list.
+func (l *List) Size() int {
+	return len(l.rules) + len(l.wildcards) + len(l.exceptions)
+}
+
+// PublicSuffix returns the public suffix of the canonical domain, e.g. 'co.uk' for 'a.example.co.uk'.
+// The last label is the public suffix if no rule matches.
+func (l *List) PublicSuffix(domain string) string {
+	labels := strings.Split(domain, ".")
+	for i := range labels {
+		suffix := strings.Join(labels[i:], ".")
+		if _, ok := l.exceptions[suffix]; ok {
+			return strings.Join(labels[i+1:], ".")
+		}
+		if _, ok := l.rules[suffix]; ok {
+			return suffix
+		}
+		if i+1 < len(labels) {
+			if _, ok := l.wildcards[strings.Join(labels[i+1:], ".")]; ok {
+				return suffix
+			}
+		}
+	}
+
+	return labels[len(labels)-1]
+}
+
+// RegistrableDomain returns the public suffix plus one label (eTLD+1), e.g. 'example.co.uk' for 'a.example.co.uk'.
+// IP addresses and public suffixes have no registrable domain.
+func (l *List) RegistrableDomain(domain string) (string, error) {
+	domain, err := util.CanonicalHost(domain)
+	if err != nil {
+		return "", err
+	}
+	if net.ParseIP(domain) != nil {
+		return "", fmt.Errorf("'%s' is an ip address", domain)
+	}
+	suffix := l.PublicSuffix(domain)
+	if domain == suffix {
+		return "", fmt.Errorf("'%s' is a public suffix", domain)
+	}
+	rest := strings.TrimSuffix(domain, "."+suffix)
+
+	return rest[strings.LastIndex(rest, ".")+1:] + "." + suffix, nil
+}
+
+// Default returns the embedded list.
+func Default() *List {
+	loadOnce.Do(func() {
+		list, err := Parse(bytes.NewReader(embeddedList))
+		if err != nil {
+			panic(fmt.Sprintf("failed to parse the embedded public suffix list. %s", err.Error()))
+		}
+		defaultList = list
+	})
+
+	return defaultList
+}
+
+// RegistrableDomain returns the registrable domain of the host using the embedded list.
+func RegistrableDomain(host string) (string, error) {
+	return Default().RegistrableDomain(host)
+}
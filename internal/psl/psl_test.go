@@ -0,0 +1,48 @@
+package psl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RegistrableDomain(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+		isError  bool
+	}{
+		{host: "example.com", expected: "example.com"},
+		{host: "www.example.com", expected: "example.com"},
+		{host: "a.b.example.com", expected: "example.com"},
+		{host: "a.example.co.uk", expected: "example.co.uk"},
+		{host: "b.example.co.uk", expected: "example.co.uk"},
+		{host: "foo.github.io", expected: "foo.github.io"},
+		{host: "x.bar.github.io", expected: "bar.github.io"},
+		{host: "WWW.Example.COM.", expected: "example.com"},
+		{host: "a.b.example.unknowntld", expected: "example.unknowntld"},
+		{host: "test.b.ck", expected: "test.b.ck"}, // *.ck
+		{host: "www.ck", expected: "www.ck"},       // !www.ck
+		{host: "a.www.ck", expected: "www.ck"},
+		{host: "食狮.com.cn", expected: "xn--85x722f.com.cn"},
+		{host: "a.xn--85x722f.xn--55qx5d.cn", expected: "xn--85x722f.xn--55qx5d.cn"},
+		{host: "com", isError: true},
+		{host: "co.uk", isError: true},
+		{host: "github.io", isError: true},
+		{host: "b.ck", isError: true},
+		{host: "127.0.0.1", isError: true},
+		{host: "", isError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			actual, err := RegistrableDomain(tt.host)
+			if tt.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package ssrf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+
+	"github.com/IliaW/rule-api/util"
+)
+
+var ErrForbiddenAddress = errors.New("address is not allowed")
+
+// blockedPrefixes are the special-purpose ranges not covered by the netip.Addr checks.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),         // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),     // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),      // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),     // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),       // reserved and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),      // NAT64, may embed an internal IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"),    // local-use NAT64
+	netip.MustParsePrefix("2002::/16"),         // 6to4, may embed an internal IPv4 address
+	netip.MustParsePrefix("fd00:ec2::254/128"), // AWS metadata, also covered by the unique local range
+}
+
+// Guard rejects connections to loopback, link-local (including the 169.254.169.254 metadata service), private,
+// multicast and other special-purpose addresses. The check is done in the dialer on the resolved address of every
+// connection, so redirects and DNS rebinding are covered as well.
+type Guard struct {
+	allowedHosts    map[string]struct{}
+	allowedPrefixes []netip.Prefix
+	lookup          LookupFunc
+}
+
+// LookupFunc returns the addresses of the host.
+type LookupFunc = func(ctx context.Context, host string) ([]netip.Addr, error)
+
+// NewGuard creates a guard. Connections to the allowed hosts (e.g. 'robots.test.internal') and to the addresses of
+// the allowed CIDRs (e.g. '10.1.0.0/16' or '127.0.0.1') are not checked. The hosts are resolved by lookup in Resolve,
+// it should be the same resolver the dialer uses. net.DefaultResolver is used if lookup is nil.
+func NewGuard(allowedHosts []string, allowedCidrs []string, lookup LookupFunc) (*Guard, error) {
+	if lookup == nil {
+		lookup = func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		}
+	}
+	g := &Guard{allowedHosts: make(map[string]struct{}, len(allowedHosts)), lookup: lookup}
+	for _, host := range allowedHosts {
+		canonical, err := util.CanonicalHost(host)
+		if err != nil {
+			return nil, err
+		}
+		g.allowedHosts[canonical] = struct{}{}
+	}
+	for _, cidr := range allowedCidrs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid allowed cidr '%s'. %s", cidr, err.Error())
+			}
+			g.allowedPrefixes = append(g.allowedPrefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed cidr '%s'. %s", cidr, err.Error())
+		}
+		g.allowedPrefixes = append(g.allowedPrefixes, prefix.Masked())
+	}
+
+	return g, nil
+}
+
+// IsAllowed reports whether a connection to the address is allowed.
+func (g *Guard) IsAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range g.allowedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		// covers unspecified, loopback, multicast and link-local addresses
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Control is used as net.Dialer.Control. It is called with the resolved address right before the connection.
+func (g *Guard) Control(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !g.IsAllowed(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr.String())
+	}
+
+	return nil
+}
+
+type DialFunc = func(ctx context.Context, network string, address string) (net.Conn, error)
+
+// DialContext returns the DialContext function of the dialer with the guard applied. The optional wrap is applied to
+// the dial functions of the dialer, e.g. to resolve the hosts with a custom resolver.
+func (g *Guard) DialContext(dialer *net.Dialer, wrap func(dial DialFunc) DialFunc) DialFunc {
+	guarded := *dialer
+	guarded.Control = g.Control
+	dial, guardedDial := dialer.DialContext, guarded.DialContext
+	if wrap != nil {
+		dial, guardedDial = wrap(dial), wrap(guardedDial)
+	}
+
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			if canonical, err := util.CanonicalHost(host); err == nil {
+				if _, ok := g.allowedHosts[canonical]; ok {
+					return dial(ctx, network, address)
+				}
+			}
+		}
+
+		return guardedDial(ctx, network, address)
+	}
+}
+
+// Resolve returns the addresses of the host if all of them are allowed. It is used for the requests through a proxy,
+// where the dialer sees the proxy address only. The proxy must connect to the returned addresses instead of resolving
+// the host again, otherwise DNS rebinding is possible. A host that can't be resolved is rejected as well.
+// Nil addresses are returned for the allowed hosts.
+func (g *Guard) Resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	canonical, err := util.CanonicalHost(host)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := g.allowedHosts[canonical]; ok {
+		return nil, nil
+	}
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(canonical); err == nil {
+		addrs = append(addrs, addr)
+	} else if addrs, err = g.lookup(ctx, canonical); err != nil {
+		return nil, fmt.Errorf("%w: failed to resolve '%s'. %s", ErrForbiddenAddress, canonical, err.Error())
+	}
+	for _, addr := range addrs {
+		if !g.IsAllowed(addr) {
+			return nil, fmt.Errorf("%w: %s", ErrForbiddenAddress, addr.String())
+		}
+	}
+
+	return addrs, nil
+}
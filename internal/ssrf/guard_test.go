@@ -0,0 +1,130 @@
+package ssrf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IsAllowed(t *testing.T) {
+	guard, err := NewGuard(nil, []string{"10.1.0.0/16", "127.0.0.2"}, nil)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		addr     string
+		expected bool
+	}{
+		{addr: "93.184.215.14", expected: true},
+		{addr: "2606:2800:21f:cb07:6820:80da:af6b:8b2c", expected: true},
+		{addr: "127.0.0.1", expected: false},
+		{addr: "::1", expected: false},
+		{addr: "0.0.0.0", expected: false},
+		{addr: "::", expected: false},
+		{addr: "169.254.169.254", expected: false},
+		{addr: "fe80::1%eth0", expected: false},
+		{addr: "10.0.0.1", expected: false},
+		{addr: "172.16.5.4", expected: false},
+		{addr: "192.168.1.1", expected: false},
+		{addr: "fd00:ec2::254", expected: false},
+		{addr: "100.64.0.1", expected: false},
+		{addr: "224.0.0.1", expected: false},
+		{addr: "ff02::1", expected: false},
+		{addr: "255.255.255.255", expected: false},
+		{addr: "::ffff:127.0.0.1", expected: false},
+		{addr: "::ffff:169.254.169.254", expected: false},
+		{addr: "64:ff9b::a00:1", expected: false},
+		{addr: "10.1.2.3", expected: true},  // allowed cidr
+		{addr: "127.0.0.2", expected: true}, // allowed ip
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.expected, guard.IsAllowed(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+func Test_DialContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /"))
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	newClient := func(guard *Guard) *http.Client {
+		return &http.Client{Transport: &http.Transport{DialContext: guard.DialContext(&net.Dialer{}, nil)}}
+	}
+
+	guard, _ := NewGuard(nil, nil, nil)
+	_, err := newClient(guard).Get(server.URL + "/robots.txt")
+	assert.True(t, errors.Is(err, ErrForbiddenAddress))
+	// the hostname is resolved to the loopback address
+	_, err = newClient(guard).Get("http://localhost:" + port + "/robots.txt")
+	assert.True(t, errors.Is(err, ErrForbiddenAddress))
+
+	// only the redirect server is allowed, the redirect to the loopback address is blocked
+	redirect := httptest.NewServer(http.RedirectHandler(server.URL+"/robots.txt", http.StatusFound))
+	defer redirect.Close()
+	_, redirectPort, _ := net.SplitHostPort(redirect.Listener.Addr().String())
+	_, err = newClient(must(NewGuard([]string{"localhost"}, nil, nil))).Get("http://localhost:" + redirectPort + "/")
+	assert.True(t, errors.Is(err, ErrForbiddenAddress))
+
+	for _, allowed := range []*Guard{
+		must(NewGuard([]string{"LOCALHOST"}, nil, nil)),
+		must(NewGuard(nil, []string{"127.0.0.0/8", "::1"}, nil)),
+	} {
+		resp, err := newClient(allowed).Get("http://localhost:" + port + "/robots.txt")
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		assert.Equal(t, "User-agent: *\nDisallow: /", string(body))
+	}
+}
+
+func must(g *Guard, err error) *Guard {
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+func Test_Resolve(t *testing.T) {
+	lookups := map[string][]netip.Addr{
+		"localhost":    {netip.MustParseAddr("127.0.0.1")},
+		"rebind.test":  {netip.MustParseAddr("93.184.215.14"), netip.MustParseAddr("10.0.0.1")},
+		"example.test": {netip.MustParseAddr("93.184.215.14")},
+	}
+	var looked []string
+	guard := must(NewGuard([]string{"robots.test.internal"}, nil, func(_ context.Context, host string) ([]netip.Addr,
+		error) {
+		looked = append(looked, host)
+		if addrs, ok := lookups[host]; ok {
+			return addrs, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}))
+	ctx := context.Background()
+
+	for _, host := range []string{"127.0.0.1", "localhost", "[fd00:ec2::254]", "rebind.test", "unresolvable.invalid"} {
+		_, err := guard.Resolve(ctx, host)
+		assert.True(t, errors.Is(err, ErrForbiddenAddress), host)
+	}
+	addrs, err := guard.Resolve(ctx, "93.184.215.14")
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("93.184.215.14")}, addrs)
+	addrs, err = guard.Resolve(ctx, "Example.test")
+	assert.NoError(t, err)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("93.184.215.14")}, addrs)
+	addrs, err = guard.Resolve(ctx, "robots.test.internal")
+	assert.NoError(t, err)
+	assert.Nil(t, addrs)
+	// the addresses are not looked up for the ips and the allowed hosts
+	assert.Equal(t, []string{"localhost", "rebind.test", "unresolvable.invalid", "example.test"}, looked)
+}
@@ -2,11 +2,14 @@ package telemetry
 
 import (
 	"context"
+	"errors"
 	"go.opentelemetry.io/otel"
 	"log/slog"
 	"os"
+	"time"
 
 	"go.opentelemetry.io/contrib/detectors/aws/ecs"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
@@ -20,8 +23,9 @@ import (
 var meter metric.Meter
 
 type MetricsProvider struct {
-	ApiMetrics *ApiMetrics
-	Close      func()
+	ApiMetrics   *ApiMetrics
+	FetchMetrics *FetchMetrics
+	Close        func()
 }
 
 type ApiMetrics struct {
@@ -29,6 +33,14 @@ type ApiMetrics struct {
 	ErrorResponseCounter   func(count int64)
 }
 
+type FetchMetrics struct {
+	ProxyRequestCounter func(proxy string)
+	DnsLookupLatency    func(latency time.Duration)
+	// DnsFailureCounter counts the failed lookups by the reason. The hosts are not the attributes, since the number
+	// of the crawled hosts is not bounded
+	DnsFailureCounter func(reason string)
+}
+
 func SetupMetrics(ctx context.Context, cfg *config.Config) *MetricsProvider {
 	metricsProvider := new(MetricsProvider)
 	var meterProvider *sdkmetric.MeterProvider
@@ -81,6 +93,37 @@ func SetupMetrics(ctx context.Context, cfg *config.Config) *MetricsProvider {
 		},
 	}
 
+	proxyRequestCounter, proxyErr := meter.Int64Counter("rule-api.fetch.proxy",
+		metric.WithDescription("The number of outbound connections per proxy. 'direct' - without a proxy."),
+		metric.WithUnit("{connections}"))
+	dnsLookupLatency, latencyErr := meter.Float64Histogram("rule-api.dns.lookup.latency",
+		metric.WithDescription("The latency of the DNS lookups that are not served from the cache."),
+		metric.WithUnit("ms"))
+	dnsFailureCounter, failureErr := meter.Int64Counter("rule-api.dns.lookup.failure",
+		metric.WithDescription("The number of failed DNS lookups per reason: not_found, timeout, temporary or other."),
+		metric.WithUnit("{lookups}"))
+	if err = errors.Join(proxyErr, latencyErr, failureErr); err != nil {
+		slog.Error("failed to create telemetry counters for the fetch client.", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	metricsProvider.FetchMetrics = &FetchMetrics{
+		ProxyRequestCounter: func(proxy string) {
+			if cfg.TelemetrySettings.Enabled {
+				proxyRequestCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("proxy", proxy)))
+			}
+		},
+		DnsLookupLatency: func(latency time.Duration) {
+			if cfg.TelemetrySettings.Enabled {
+				dnsLookupLatency.Record(ctx, float64(latency.Microseconds())/1000)
+			}
+		},
+		DnsFailureCounter: func(reason string) {
+			if cfg.TelemetrySettings.Enabled {
+				dnsFailureCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason)))
+			}
+		},
+	}
+
 	// initialize metrics in DataDog for setup UI
 	if cfg.TelemetrySettings.Enabled {
 		metricsProvider.ApiMetrics.SuccessResponseCounter(1)
@@ -15,7 +15,9 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -23,7 +25,12 @@ import (
 	docs "github.com/IliaW/rule-api/docs"
 	"github.com/IliaW/rule-api/handler"
 	cacheClient "github.com/IliaW/rule-api/internal/cache"
+	"github.com/IliaW/rule-api/internal/dns"
+	"github.com/IliaW/rule-api/internal/grpcserver"
+	"github.com/IliaW/rule-api/internal/jobs"
 	"github.com/IliaW/rule-api/internal/persistence"
+	"github.com/IliaW/rule-api/internal/proxy"
+	"github.com/IliaW/rule-api/internal/ssrf"
 	"github.com/IliaW/rule-api/internal/telemetry"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -38,6 +45,7 @@ var (
 	cache      cacheClient.CachedClient
 	db         *sql.DB
 	ruleRepo   persistence.RuleStorage
+	jobRepo    persistence.JobStorage
 	httpClient *http.Client
 	metrics    *telemetry.MetricsProvider
 )
@@ -56,15 +64,17 @@ func main() {
 	db = setupDatabase()
 	defer closeDatabase()
 	ruleRepo = persistence.NewRuleRepository(db)
+	jobRepo = persistence.NewJobRepository(db)
 	cache = cacheClient.NewMemcachedClient(cfg.CacheSettings)
 	defer cache.Close()
 	httpClient = setupHttpClient()
 	slog.Info("starting application on port "+cfg.Port, slog.String("env", cfg.Env))
 
+	ruleApiHandler := handler.NewRuleApiHandler(cfg, cache, ruleRepo, httpClient, metrics.ApiMetrics)
 	port := fmt.Sprintf(":%v", cfg.Port)
 	srv := &http.Server{
 		Addr:    port,
-		Handler: httpServer().Handler(),
+		Handler: httpServer(ruleApiHandler).Handler(),
 	}
 	go func() {
 		if err := srv.ListenAndServe(); err != nil {
@@ -72,12 +82,38 @@ func main() {
 			os.Exit(1)
 		}
 	}()
+	var grpcSrv *grpcserver.Server
+	if cfg.GrpcSettings != nil && cfg.GrpcSettings.Enabled {
+		grpcSrv = startGrpcServer(ruleApiHandler)
+	}
+	var jobRunner *jobs.Runner
+	if jobsEnabled() {
+		jobRunner = jobs.NewRunner(cfg.JobSettings, jobRepo, ruleApiHandler)
+		jobRunner.Start()
+	}
 
 	<-ctx.Done()
 	slog.Info("stopping server...")
 	ctxT, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	// the servers and the job workers are stopped concurrently, so each of them has the whole timeout
+	var wg sync.WaitGroup
+	if grpcSrv != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			grpcSrv.Stop(ctxT)
+		}()
+	}
+	if jobRunner != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			jobRunner.Stop(ctxT)
+		}()
+	}
 	err := srv.Shutdown(ctxT)
+	wg.Wait()
 	if errors.Is(err, context.DeadlineExceeded) {
 		slog.Error("shutdown timeout exceeded")
 		os.Exit(1)
@@ -85,11 +121,12 @@ func main() {
 	slog.Info("server stopped.")
 }
 
-func httpServer() *gin.Engine {
+func httpServer(ruleApiHandler *handler.RuleApiHandler) *gin.Engine {
 	setupGinMod()
 	r := gin.New()
 	r.UseH2C = true
 	r.Use(gin.Recovery())
+	r.Use(handler.RequestId())
 	r.Use(setCORS())
 	r.Use(limitBodySize())
 	r.Use(gin.LoggerWithConfig(gin.LoggerConfig{SkipPaths: []string{"/ping", "/swagger"}}))
@@ -97,16 +134,32 @@ func httpServer() *gin.Engine {
 		c.JSON(http.StatusOK, gin.H{"message": "pong"})
 	})
 
-	ruleApiHandler := handler.NewRuleApiHandler(cfg, cache, ruleRepo, httpClient, metrics.ApiMetrics)
 	crawlAllowed := r.Group(cfg.RuleApiUrlPath)
 	crawlAllowed.GET("/crawl-allowed", ruleApiHandler.GetAllowedCrawl)
+	crawlAllowed.POST(crawlStreamPath, ruleApiHandler.StreamAllowedCrawl)
+	crawlAllowed.GET("/tdm-reservation", ruleApiHandler.GetTdmReservation)
+	crawlAllowed.GET("/page-directives", ruleApiHandler.GetPageDirectives)
 
 	customRule := r.Group(cfg.RuleApiUrlPath)
 	customRule.Use(apiKeyCheck())
 	customRule.GET("/custom-rule", ruleApiHandler.GetCustomRule)
+	customRule.GET("/custom-rules", ruleApiHandler.GetCustomRules)
 	customRule.POST("/custom-rule", ruleApiHandler.CreateCustomRule)
 	customRule.PUT("/custom-rule", ruleApiHandler.UpdateCustomRule)
 	customRule.DELETE("/custom-rule", ruleApiHandler.DeleteCustomRule)
+	customRule.POST("/custom-rule/compare", ruleApiHandler.CompareCustomRule)
+	customRule.GET("/domain-alias", ruleApiHandler.GetDomainAliases)
+	customRule.POST("/domain-alias", ruleApiHandler.CreateDomainAlias)
+	customRule.DELETE("/domain-alias", ruleApiHandler.DeleteDomainAlias)
+
+	if jobsEnabled() {
+		jobHandler := handler.NewJobHandler(cfg, jobRepo)
+		job := r.Group(cfg.RuleApiUrlPath)
+		job.Use(apiKeyCheck())
+		job.POST(jobsPath, jobHandler.CreateJob)
+		job.GET("/jobs/:id", jobHandler.GetJob)
+		job.GET("/jobs/:id/result", jobHandler.GetJobResult)
+	}
 
 	docs.SwaggerInfo.Title = fmt.Sprintf("Rule API (%s)", cfg.ServiceName)
 	docs.SwaggerInfo.Description = "This API controls crawl permissions and creates custom rules for specific domains."
@@ -117,13 +170,33 @@ func httpServer() *gin.Engine {
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
 	r.NoRoute(func(c *gin.Context) {
-		c.AbortWithStatusJSON(http.StatusNotFound,
-			gin.H{"message": fmt.Sprintf("no route found for %s %s", c.Request.Method, c.Request.URL)})
+		handler.AbortWithError(c, http.StatusNotFound,
+			fmt.Sprintf("no route found for %s %s", c.Request.Method, c.Request.URL), nil)
 	})
 
 	return r
 }
 
+// startGrpcServer serves the grpc API on the separate port. The decisions are made by the same handler as in
+// the http API.
+func startGrpcServer(ruleApiHandler *handler.RuleApiHandler) *grpcserver.Server {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%v", cfg.GrpcSettings.Port))
+	if err != nil {
+		slog.Error("failed to listen grpc port.", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	grpcSrv := grpcserver.NewServer(cfg.GrpcSettings, ruleApiHandler)
+	slog.Info("starting grpc server on port " + cfg.GrpcSettings.Port)
+	go func() {
+		if err := grpcSrv.Serve(listener); err != nil {
+			slog.Error("grpc serve:", slog.Any("err", err))
+			os.Exit(1)
+		}
+	}()
+
+	return grpcSrv
+}
+
 func setCORS() gin.HandlerFunc {
 	return cors.New(cors.Config{
 		AllowOriginFunc: func(origin string) bool { //allow all origins and echoes back the caller domain
@@ -131,14 +204,32 @@ func setCORS() gin.HandlerFunc {
 		},
 		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
 		AllowHeaders: []string{"Content-Type", "Content-Length", "Accept-Encoding", "Authorization", "X-Forwarded-For",
-			"X-CSRF-Token", "X-Max"},
+			"X-CSRF-Token", "X-Max", handler.RequestIdHeader},
+		ExposeHeaders:    []string{handler.RequestIdHeader},
 		AllowCredentials: true,
 		MaxAge:           cfg.CorsMaxAgeHours,
 	})
 }
 
+const (
+	crawlStreamPath = "/crawl-allowed/stream"
+	jobsPath        = "/jobs"
+)
+
+func jobsEnabled() bool {
+	return cfg.JobSettings != nil && cfg.JobSettings.Enabled
+}
+
 func limitBodySize() gin.HandlerFunc {
+	// the uploaded url lists have own limit
+	exemptPaths := []string{cfg.RuleApiUrlPath + jobsPath}
+	if cfg.CrawlStreamSettings != nil && cfg.CrawlStreamSettings.ExemptBodyLimit {
+		exemptPaths = append(exemptPaths, cfg.RuleApiUrlPath+crawlStreamPath)
+	}
 	return func(c *gin.Context) {
+		if slices.Contains(exemptPaths, c.FullPath()) {
+			return
+		}
 		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxBodySize*1024*1024)
 	}
 }
@@ -147,8 +238,7 @@ func apiKeyCheck() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		apiKey := c.GetHeader("X-API-Key")
 		if apiKey == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "X-API-Key header is missing"})
-			c.Abort()
+			handler.AbortWithError(c, http.StatusUnauthorized, "X-API-Key header is missing", nil)
 			return
 		}
 
@@ -159,19 +249,16 @@ func apiKeyCheck() gin.HandlerFunc {
 			Scan(&isActive)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid api-key"})
-				c.Abort()
+				handler.AbortWithError(c, http.StatusUnauthorized, "invalid api-key", nil)
 				return
 			}
 			slog.Error("failed to query api key", slog.String("err", err.Error()))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "api-key check failed"})
-			c.Abort()
+			handler.AbortWithError(c, http.StatusInternalServerError, "api-key check failed", nil)
 			return
 		}
 
 		if !isActive {
-			c.JSON(http.StatusForbidden, gin.H{"error": "api-key is not active"})
-			c.Abort()
+			handler.AbortWithError(c, http.StatusForbidden, "api-key is not active", nil)
 			return
 		}
 
@@ -285,16 +372,71 @@ func closeDatabase() {
 }
 
 func setupHttpClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   cfg.HttpClientSettings.DialTimeout,
+		KeepAlive: cfg.HttpClientSettings.DialKeepAlive,
+	}
+	proxySelector, err := proxy.NewSelector(cfg.HttpClientSettings.Proxy, func(host string, proxyUrl string) {
+		slog.Debug("outbound connection.", slog.String("host", host), slog.String("proxy", proxyUrl))
+		metrics.FetchMetrics.ProxyRequestCounter(proxyUrl)
+	})
+	if err != nil {
+		slog.Error("failed to setup proxy.", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+
+	var resolve func(dial dns.DialFunc) dns.DialFunc
+	var lookup ssrf.LookupFunc
+	if dnsCfg := cfg.HttpClientSettings.Dns; dnsCfg != nil && dnsCfg.Enabled {
+		resolver := dns.NewResolver(dns.Options{
+			Server:        dnsCfg.Server,
+			PositiveTtl:   dnsCfg.PositiveTtl,
+			NegativeTtl:   dnsCfg.NegativeTtl,
+			HappyEyeballs: dnsCfg.HappyEyeballs,
+			FallbackDelay: dnsCfg.FallbackDelay,
+			OnLookup: func(host string, latency time.Duration, err error) {
+				metrics.FetchMetrics.DnsLookupLatency(latency)
+				if err != nil {
+					// the metric is counted per reason, the host of the failure is in the log
+					reason := dns.FailureReason(err)
+					slog.Warn("dns lookup failed.", slog.String("host", host), slog.String("reason", reason),
+						slog.String("err", err.Error()))
+					metrics.FetchMetrics.DnsFailureCounter(reason)
+				}
+			},
+		})
+		resolve = resolver.DialContext
+		// the guard checks the targets behind the proxies with the same resolver and metrics
+		lookup = resolver.LookupNetIP
+	}
+
+	dialContext := dialer.DialContext
+	if resolve != nil {
+		dialContext = resolve(dialContext)
+	}
+	// the proxies are usually internal hosts. They are dialed without the ssrf protection, the targets behind them
+	// are checked by the guard
+	proxyDial := dialContext
+	var resolveTarget proxy.ResolveFunc
+	if ssrfCfg := cfg.HttpClientSettings.SsrfProtection; ssrfCfg != nil && ssrfCfg.Enabled {
+		guard, err := ssrf.NewGuard(ssrfCfg.AllowedHosts, ssrfCfg.AllowedCidrs, lookup)
+		if err != nil {
+			slog.Error("failed to setup ssrf protection.", slog.String("err", err.Error()))
+			os.Exit(1)
+		}
+		dialContext = guard.DialContext(dialer, resolve)
+		resolveTarget = guard.Resolve
+	} else {
+		slog.Warn("ssrf protection is disabled.")
+	}
+
 	transport := &http.Transport{
 		MaxIdleConns:        cfg.HttpClientSettings.MaxIdleConnections,
 		MaxIdleConnsPerHost: cfg.HttpClientSettings.MaxIdleConnectionsPerHost,
 		MaxConnsPerHost:     cfg.HttpClientSettings.MaxConnectionsPerHost,
 		IdleConnTimeout:     cfg.HttpClientSettings.IdleConnectionTimeout,
 		TLSHandshakeTimeout: cfg.HttpClientSettings.TlsHandshakeTimeout,
-		DialContext: (&net.Dialer{
-			Timeout:   cfg.HttpClientSettings.DialTimeout,
-			KeepAlive: cfg.HttpClientSettings.DialKeepAlive,
-		}).DialContext,
+		DialContext:         proxySelector.DialContext(dialContext, proxyDial, resolveTarget),
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: cfg.HttpClientSettings.TlsInsecureSkipVerify,
 		},
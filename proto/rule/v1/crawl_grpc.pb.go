@@ -0,0 +1,204 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: rule/v1/crawl.proto
+
+package rulev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CrawlService_CheckCrawl_FullMethodName       = "/rule.v1.CrawlService/CheckCrawl"
+	CrawlService_CheckCrawlBatch_FullMethodName  = "/rule.v1.CrawlService/CheckCrawlBatch"
+	CrawlService_CheckCrawlStream_FullMethodName = "/rule.v1.CrawlService/CheckCrawlStream"
+)
+
+// CrawlServiceClient is the client API for CrawlService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CrawlService checks if crawling is allowed for a user agent and a url. The verdicts are the same as the ones of the
+// /crawl-allowed http endpoint.
+type CrawlServiceClient interface {
+	// CheckCrawl checks one url.
+	CheckCrawl(ctx context.Context, in *CheckCrawlRequest, opts ...grpc.CallOption) (*CheckCrawlResponse, error)
+	// CheckCrawlBatch checks several urls. The responses are in the order of the requests.
+	CheckCrawlBatch(ctx context.Context, in *CheckCrawlBatchRequest, opts ...grpc.CallOption) (*CheckCrawlBatchResponse, error)
+	// CheckCrawlStream checks the urls as they are sent. The responses are in the order of the requests.
+	CheckCrawlStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CheckCrawlRequest, CheckCrawlResponse], error)
+}
+
+type crawlServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCrawlServiceClient(cc grpc.ClientConnInterface) CrawlServiceClient {
+	return &crawlServiceClient{cc}
+}
+
+func (c *crawlServiceClient) CheckCrawl(ctx context.Context, in *CheckCrawlRequest, opts ...grpc.CallOption) (*CheckCrawlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckCrawlResponse)
+	err := c.cc.Invoke(ctx, CrawlService_CheckCrawl_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crawlServiceClient) CheckCrawlBatch(ctx context.Context, in *CheckCrawlBatchRequest, opts ...grpc.CallOption) (*CheckCrawlBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckCrawlBatchResponse)
+	err := c.cc.Invoke(ctx, CrawlService_CheckCrawlBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crawlServiceClient) CheckCrawlStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[CheckCrawlRequest, CheckCrawlResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CrawlService_ServiceDesc.Streams[0], CrawlService_CheckCrawlStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CheckCrawlRequest, CheckCrawlResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrawlService_CheckCrawlStreamClient = grpc.BidiStreamingClient[CheckCrawlRequest, CheckCrawlResponse]
+
+// CrawlServiceServer is the server API for CrawlService service.
+// All implementations must embed UnimplementedCrawlServiceServer
+// for forward compatibility.
+//
+// CrawlService checks if crawling is allowed for a user agent and a url. The verdicts are the same as the ones of the
+// /crawl-allowed http endpoint.
+type CrawlServiceServer interface {
+	// CheckCrawl checks one url.
+	CheckCrawl(context.Context, *CheckCrawlRequest) (*CheckCrawlResponse, error)
+	// CheckCrawlBatch checks several urls. The responses are in the order of the requests.
+	CheckCrawlBatch(context.Context, *CheckCrawlBatchRequest) (*CheckCrawlBatchResponse, error)
+	// CheckCrawlStream checks the urls as they are sent. The responses are in the order of the requests.
+	CheckCrawlStream(grpc.BidiStreamingServer[CheckCrawlRequest, CheckCrawlResponse]) error
+	mustEmbedUnimplementedCrawlServiceServer()
+}
+
+// UnimplementedCrawlServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCrawlServiceServer struct{}
+
+func (UnimplementedCrawlServiceServer) CheckCrawl(context.Context, *CheckCrawlRequest) (*CheckCrawlResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckCrawl not implemented")
+}
+func (UnimplementedCrawlServiceServer) CheckCrawlBatch(context.Context, *CheckCrawlBatchRequest) (*CheckCrawlBatchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckCrawlBatch not implemented")
+}
+func (UnimplementedCrawlServiceServer) CheckCrawlStream(grpc.BidiStreamingServer[CheckCrawlRequest, CheckCrawlResponse]) error {
+	return status.Error(codes.Unimplemented, "method CheckCrawlStream not implemented")
+}
+func (UnimplementedCrawlServiceServer) mustEmbedUnimplementedCrawlServiceServer() {}
+func (UnimplementedCrawlServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeCrawlServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CrawlServiceServer will
+// result in compilation errors.
+type UnsafeCrawlServiceServer interface {
+	mustEmbedUnimplementedCrawlServiceServer()
+}
+
+func RegisterCrawlServiceServer(s grpc.ServiceRegistrar, srv CrawlServiceServer) {
+	// If the following call panics, it indicates UnimplementedCrawlServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CrawlService_ServiceDesc, srv)
+}
+
+func _CrawlService_CheckCrawl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckCrawlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrawlServiceServer).CheckCrawl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrawlService_CheckCrawl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrawlServiceServer).CheckCrawl(ctx, req.(*CheckCrawlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrawlService_CheckCrawlBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckCrawlBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrawlServiceServer).CheckCrawlBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrawlService_CheckCrawlBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrawlServiceServer).CheckCrawlBatch(ctx, req.(*CheckCrawlBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrawlService_CheckCrawlStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CrawlServiceServer).CheckCrawlStream(&grpc.GenericServerStream[CheckCrawlRequest, CheckCrawlResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrawlService_CheckCrawlStreamServer = grpc.BidiStreamingServer[CheckCrawlRequest, CheckCrawlResponse]
+
+// CrawlService_ServiceDesc is the grpc.ServiceDesc for CrawlService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CrawlService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "rule.v1.CrawlService",
+	HandlerType: (*CrawlServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckCrawl",
+			Handler:    _CrawlService_CheckCrawl_Handler,
+		},
+		{
+			MethodName: "CheckCrawlBatch",
+			Handler:    _CrawlService_CheckCrawlBatch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CheckCrawlStream",
+			Handler:       _CrawlService_CheckCrawlStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "rule/v1/crawl.proto",
+}
@@ -0,0 +1,4 @@
+// Package rulev1 contains the generated code of the rule.v1 grpc API.
+package rulev1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative rule/v1/crawl.proto
@@ -0,0 +1,120 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	ContentRobotsTxt = "robots.txt"
+	ContentHtml      = "html"
+	ContentJson      = "json"
+	ContentBinary    = "binary"
+)
+
+var (
+	bomUtf8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUtf16Le = []byte{0xFF, 0xFE}
+	bomUtf16Be = []byte{0xFE, 0xFF}
+)
+
+// DecodeText converts the body to UTF-8. The encoding is taken from the byte order mark, then from the charset of the
+// Content-Type header. Without both, UTF-16 is detected by the zero bytes and invalid UTF-8 is decoded as
+// windows-1252, the superset of Latin-1. Returns the decoded body, the name of the source encoding and whether the
+// body had a BOM.
+func DecodeText(body []byte, contentType string) ([]byte, string, bool, error) {
+	name, bom := "", false
+	switch {
+	case bytes.HasPrefix(body, bomUtf8):
+		return body[len(bomUtf8):], "utf-8", true, nil
+	case bytes.HasPrefix(body, bomUtf16Le):
+		name, bom = "utf-16le", true
+	case bytes.HasPrefix(body, bomUtf16Be):
+		name, bom = "utf-16be", true
+	}
+	if name == "" {
+		if _, params, err := mime.ParseMediaType(contentType); err == nil {
+			name = strings.ToLower(strings.TrimSpace(params["charset"]))
+		}
+	}
+	if name == "" || name == "utf-16" {
+		name = sniffUtf16(body, name)
+	}
+
+	switch name {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		if utf8.Valid(body) {
+			return body, "utf-8", false, nil
+		}
+		// the charset is missing or wrong, Latin-1 is the most common one
+		name = "windows-1252"
+	}
+	encoding, canonicalName := charset.Lookup(name)
+	if encoding == nil {
+		return body, name, bom, fmt.Errorf("unsupported charset '%s'", name)
+	}
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, canonicalName, bom, fmt.Errorf("failed to decode %s. %s", canonicalName, err.Error())
+	}
+
+	// the decoder of UTF-16 removes the BOM, the others keep it as U+FEFF
+	return bytes.TrimPrefix(decoded, bomUtf8), canonicalName, bom, nil
+}
+
+// sniffUtf16 detects UTF-16 without a BOM by the zero bytes of ASCII characters, e.g. 'U\x00s\x00e\x00r\x00'.
+func sniffUtf16(body []byte, fallback string) string {
+	n := min(len(body)-len(body)%2, 512)
+	if n < 4 {
+		return fallback
+	}
+	var evenZeros, oddZeros int
+	for i := 0; i < n; i += 2 {
+		if body[i] == 0 {
+			evenZeros++
+		}
+		if body[i+1] == 0 {
+			oddZeros++
+		}
+	}
+	half := n / 2
+	switch {
+	case oddZeros > half*3/4 && evenZeros == 0:
+		return "utf-16le"
+	case evenZeros > half*3/4 && oddZeros == 0:
+		return "utf-16be"
+	default:
+		return fallback
+	}
+}
+
+// DetectContent detects what the decoded body is: a robots.txt file, an html page, a json document or binary data.
+// The body is checked rather than the Content-Type header, since many servers send robots.txt with a wrong one.
+func DetectContent(body []byte) string {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return ContentRobotsTxt
+	}
+	if bytes.IndexByte(trimmed, 0) >= 0 || !utf8.Valid(trimmed) {
+		return ContentBinary
+	}
+	if trimmed[0] == '<' {
+		head := strings.ToLower(string(trimmed[:min(len(trimmed), 1024)]))
+		for _, tag := range []string{"<!doctype html", "<html", "<head", "<body", "<script", "<meta", "<title"} {
+			if strings.Contains(head, tag) {
+				return ContentHtml
+			}
+		}
+	}
+	if (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		return ContentJson
+	}
+
+	return ContentRobotsTxt
+}
@@ -0,0 +1,121 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DecodeText(t *testing.T) {
+	robotsTxt := "User-agent: *\nDisallow: /privé"
+	tests := []struct {
+		name            string
+		body            string
+		contentType     string
+		expected        string
+		expectedCharset string
+		expectedBom     bool
+	}{
+		{
+			name:            "utf-8",
+			body:            robotsTxt,
+			contentType:     "text/plain; charset=utf-8",
+			expected:        robotsTxt,
+			expectedCharset: "utf-8",
+		},
+		{
+			name:            "utf-8 without content type",
+			body:            robotsTxt,
+			expected:        robotsTxt,
+			expectedCharset: "utf-8",
+		},
+		{
+			name:            "utf-8 with bom",
+			body:            "\xef\xbb\xbf" + robotsTxt,
+			contentType:     "text/plain; charset=iso-8859-1",
+			expected:        robotsTxt,
+			expectedCharset: "utf-8",
+			expectedBom:     true,
+		},
+		{
+			name:            "utf-16le with bom",
+			body:            "\xff\xfe" + "U\x00s\x00e\x00r\x00-\x00a\x00g\x00e\x00n\x00t\x00:\x00 \x00*\x00",
+			contentType:     "text/plain",
+			expected:        "User-agent: *",
+			expectedCharset: "utf-16le",
+			expectedBom:     true,
+		},
+		{
+			name:            "utf-16be with bom",
+			body:            "\xfe\xff" + "\x00U\x00s\x00e\x00r\x00-\x00a\x00g\x00e\x00n\x00t\x00:\x00 \x00*",
+			expected:        "User-agent: *",
+			expectedCharset: "utf-16be",
+			expectedBom:     true,
+		},
+		{
+			name:            "utf-16le without bom",
+			body:            "U\x00s\x00e\x00r\x00-\x00a\x00g\x00e\x00n\x00t\x00:\x00 \x00*\x00",
+			expected:        "User-agent: *",
+			expectedCharset: "utf-16le",
+		},
+		{
+			name:            "latin-1 from content type",
+			body:            "User-agent: *\nDisallow: /priv\xe9",
+			contentType:     "text/plain; charset=ISO-8859-1",
+			expected:        robotsTxt,
+			expectedCharset: "windows-1252",
+		},
+		{
+			name:            "latin-1 without content type",
+			body:            "User-agent: *\nDisallow: /priv\xe9",
+			expected:        robotsTxt,
+			expectedCharset: "windows-1252",
+		},
+		{
+			name:            "latin-1 with wrong charset",
+			body:            "User-agent: *\nDisallow: /priv\xe9",
+			contentType:     "text/plain; charset=utf-8",
+			expected:        robotsTxt,
+			expectedCharset: "windows-1252",
+		},
+		{
+			name:            "empty body",
+			body:            "",
+			expected:        "",
+			expectedCharset: "utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, charset, bom, err := DecodeText([]byte(tt.body), tt.contentType)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(actual))
+			assert.Equal(t, tt.expectedCharset, charset)
+			assert.Equal(t, tt.expectedBom, bom)
+		})
+	}
+}
+
+func Test_DetectContent(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected string
+	}{
+		{body: "User-agent: *\nDisallow: /", expected: ContentRobotsTxt},
+		{body: "", expected: ContentRobotsTxt},
+		{body: "# <html> in a comment\nUser-agent: *", expected: ContentRobotsTxt},
+		{body: "\n  <!DOCTYPE html><html><body>Not found</body></html>", expected: ContentHtml},
+		{body: "<HTML><HEAD><TITLE>404</TITLE></HEAD></HTML>", expected: ContentHtml},
+		{body: "{\"error\":\"not found\"}", expected: ContentJson},
+		{body: "[1, 2]", expected: ContentJson},
+		{body: "{ User-agent: * }", expected: ContentRobotsTxt},
+		{body: "\x1f\x8b\x08\x00\x00\x00", expected: ContentBinary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectContent([]byte(tt.body)))
+		})
+	}
+}
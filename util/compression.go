@@ -0,0 +1,86 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// AcceptEncoding lists the content encodings supported by Decompress.
+const AcceptEncoding = "gzip, deflate, br"
+
+var ErrBodyTooLarge = errors.New("body is too large")
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Decompress returns the reader of the decoded body. contentEncoding is the value of the Content-Encoding header,
+// e.g. 'gzip' or 'gzip, br'. The encodings are removed in the reverse order. Bodies compressed with gzip without the
+// header (e.g. robots.txt.gz served as is) are detected by the gzip magic number.
+func Decompress(body io.Reader, contentEncoding string) (io.Reader, error) {
+	var encodings []string
+	for _, encoding := range strings.Split(contentEncoding, ",") {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if encoding != "" && encoding != "identity" {
+			encodings = append(encodings, encoding)
+		}
+	}
+	if len(encodings) == 0 {
+		buffered := bufio.NewReader(body)
+		if magic, _ := buffered.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+			encodings = []string{"gzip"}
+		}
+		body = buffered
+	}
+
+	var err error
+	for i := len(encodings) - 1; i >= 0; i-- {
+		switch encodings[i] {
+		case "gzip", "x-gzip":
+			body, err = gzip.NewReader(body)
+		case "deflate":
+			body, err = newDeflateReader(body)
+		case "br":
+			body = brotli.NewReader(body)
+		default:
+			return nil, fmt.Errorf("unsupported content encoding '%s'", encodings[i])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s. %s", encodings[i], err.Error())
+		}
+	}
+
+	return body, nil
+}
+
+// newDeflateReader reads the zlib format of the 'deflate' encoding. Raw deflate data is accepted too,
+// since some servers send it instead.
+func newDeflateReader(body io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(body)
+	header, _ := buffered.Peek(2)
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+
+	return flate.NewReader(buffered), nil
+}
+
+// ReadAllLimit reads the reader to the end. Returns ErrBodyTooLarge if there are more than limit bytes.
+func ReadAllLimit(reader io.Reader, limit int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w. The limit is %d bytes", ErrBodyTooLarge, limit)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,87 @@
+package util
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Decompress(t *testing.T) {
+	robotsTxt := "User-agent: *\nDisallow: /private"
+	tests := []struct {
+		name            string
+		body            []byte
+		contentEncoding string
+		isError         bool
+	}{
+		{name: "identity", body: []byte(robotsTxt), contentEncoding: ""},
+		{name: "explicit identity", body: []byte(robotsTxt), contentEncoding: "identity"},
+		{name: "gzip", body: compress(t, "gzip", robotsTxt), contentEncoding: "gzip"},
+		{name: "x-gzip", body: compress(t, "gzip", robotsTxt), contentEncoding: "X-GZIP"},
+		{name: "gzip without header", body: compress(t, "gzip", robotsTxt), contentEncoding: ""},
+		{name: "deflate", body: compress(t, "deflate", robotsTxt), contentEncoding: "deflate"},
+		{name: "raw deflate", body: compress(t, "raw-deflate", robotsTxt), contentEncoding: "deflate"},
+		{name: "brotli", body: compress(t, "br", robotsTxt), contentEncoding: "br"},
+		{
+			name:            "gzip then brotli",
+			body:            compress(t, "br", string(compress(t, "gzip", robotsTxt))),
+			contentEncoding: "gzip, br",
+		},
+		{name: "unsupported encoding", body: []byte(robotsTxt), contentEncoding: "zstd", isError: true},
+		{name: "invalid gzip", body: []byte(robotsTxt), contentEncoding: "gzip", isError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := Decompress(bytes.NewReader(tt.body), tt.contentEncoding)
+			if tt.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			actual, err := io.ReadAll(reader)
+			assert.NoError(t, err)
+			assert.Equal(t, robotsTxt, string(actual))
+		})
+	}
+}
+
+func Test_ReadAllLimit(t *testing.T) {
+	// 10 MB of zeros is compressed to about 10 KB
+	bomb := compress(t, "gzip", strings.Repeat("\x00", 10<<20))
+	reader, err := Decompress(bytes.NewReader(bomb), "gzip")
+	assert.NoError(t, err)
+	_, err = ReadAllLimit(reader, 512*1024)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+
+	body, err := ReadAllLimit(strings.NewReader("1234"), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "1234", string(body))
+}
+
+func compress(t *testing.T, encoding string, s string) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	}
+	_, err := w.Write([]byte(s))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+		err      error
+	}{
+		{
+			name:     "equal",
+			a:        "User-agent: *\r\nDisallow: /private\r\n",
+			b:        "User-agent: *\nDisallow: /private",
+			expected: "",
+		},
+		{
+			name: "changed line",
+			a:    "User-agent: *\nDisallow: /private\nAllow: /public",
+			b:    "User-agent: *\nDisallow: /\nAllow: /public",
+			expected: "--- a\n+++ b\n@@ -1,3 +1,3 @@\n User-agent: *\n-Disallow: /private\n+Disallow: /\n" +
+				" Allow: /public\n",
+		},
+		{
+			name: "large files with a small change",
+			a:    robotsLines(50_000, "/a"),
+			b:    robotsLines(50_000, "/b"),
+			expected: "--- a\n+++ b\n@@ -49997,4 +49997,4 @@\n Disallow: /49996\n Disallow: /49997\n" +
+				" Disallow: /49998\n-Disallow: /a\n+Disallow: /b\n",
+		},
+		{
+			name: "too large",
+			a:    robotsLines(50_000, "/a"),
+			b:    strings.ReplaceAll(robotsLines(50_000, "/b"), "Disallow", "Allow"),
+			err:  ErrTooLargeToDiff,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			diff, err := UnifiedDiff(test.a, test.b, "a", "b")
+
+			assert.ErrorIs(tt, err, test.err)
+			assert.Equal(tt, test.expected, diff)
+		})
+	}
+}
+
+// robotsLines returns the robots.txt file with n - 1 numbered rules followed by the last rule.
+func robotsLines(n int, last string) string {
+	var sb strings.Builder
+	for i := 0; i < n-1; i++ {
+		sb.WriteString(fmt.Sprintf("Disallow: /%d\n", i))
+	}
+	sb.WriteString("Disallow: " + last)
+	return sb.String()
+}
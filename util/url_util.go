@@ -2,7 +2,24 @@ package util
 
 import (
 	"errors"
+	"fmt"
+	"net"
 	u "net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// hostProfile converts IDNs to punycode. Underscores are allowed since they are common in real hostnames.
+var hostProfile = idna.New(
+	idna.MapForLookup(),
+	idna.Transitional(false),
+	idna.StrictDomainName(false),
 )
 
 func GetDomain(url string) (string, error) {
@@ -14,7 +31,7 @@ func GetDomain(url string) (string, error) {
 		return "", errors.New("invalid url. Url should contain scheme and hostname")
 	}
 
-	return parsedUrl.Hostname(), nil
+	return CanonicalHost(parsedUrl.Hostname())
 }
 
 func GetBaseUrl(url string) (string, error) {
@@ -25,6 +42,144 @@ func GetBaseUrl(url string) (string, error) {
 	if parsedUrl.Scheme == "" || parsedUrl.Hostname() == "" {
 		return "", errors.New("invalid url. Url should contain scheme and hostname")
 	}
+	scheme := strings.ToLower(parsedUrl.Scheme)
+	host, err := canonicalHostPort(scheme, parsedUrl.Hostname(), parsedUrl.Port())
+	if err != nil {
+		return "", err
+	}
+
+	return scheme + "://" + host, nil
+}
+
+// CanonicalHost lowercases the host, converts IDNs to punycode and strips the trailing dot.
+// IP addresses are returned in their canonical form.
+func CanonicalHost(host string) (string, error) {
+	host = strings.TrimSuffix(strings.TrimSpace(host), ".")
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "" {
+		return "", errors.New("invalid url. Url should contain scheme and hostname")
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String(), nil
+	}
+	ascii, err := hostProfile.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid hostname '%s'. %s", host, err.Error())
+	}
+
+	return strings.ToLower(ascii), nil
+}
+
+// CanonicalizeUrl returns the url with the canonical scheme and host, without the default port and the fragment.
+// Percent-encoding in the path and query is normalized: unreserved characters are decoded and hex digits
+// are uppercased, so equal urls produce equal strings.
+func CanonicalizeUrl(url string) (string, error) {
+	parsedUrl, err := u.Parse(strings.TrimSpace(url))
+	if err != nil {
+		return "", err
+	}
+	if parsedUrl.Scheme == "" || parsedUrl.Hostname() == "" {
+		return "", errors.New("invalid url. Url should contain scheme and hostname")
+	}
+	scheme := strings.ToLower(parsedUrl.Scheme)
+	host, err := canonicalHostPort(scheme, parsedUrl.Hostname(), parsedUrl.Port())
+	if err != nil {
+		return "", err
+	}
+
+	path := normalizePercentEncoding(parsedUrl.EscapedPath())
+	if path == "" {
+		path = "/"
+	}
+	canonical := scheme + "://" + host + path
+	if parsedUrl.RawQuery != "" {
+		canonical += "?" + normalizePercentEncoding(parsedUrl.RawQuery)
+	}
+
+	return canonical, nil
+}
+
+func canonicalHostPort(scheme string, hostname string, port string) (string, error) {
+	host, err := CanonicalHost(hostname)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6 literal
+	}
+	if port != "" && port != defaultPorts[scheme] {
+		host += ":" + port
+	}
+
+	return host, nil
+}
+
+// normalizePercentEncoding decodes percent-encoded unreserved characters (RFC 3986, section 2.3)
+// and uppercases the hex digits of the rest.
+func normalizePercentEncoding(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		b := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(b) {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+		i += 2
+	}
+
+	return sb.String()
+}
+
+func isUnreserved(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' ||
+		b == '-' || b == '.' || b == '_' || b == '~'
+}
+
+func isHex(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'f' || b >= 'A' && b <= 'F'
+}
+
+func unhex(b byte) byte {
+	switch {
+	case b >= '0' && b <= '9':
+		return b - '0'
+	case b >= 'a' && b <= 'f':
+		return b - 'a' + 10
+	default:
+		return b - 'A' + 10
+	}
+}
+
+// HasScheme reports whether the url starts with a scheme, e.g. 'https://'.
+// Urls like 'example.com/path' and '//example.com/path' have no scheme.
+func HasScheme(url string) bool {
+	scheme, _, found := strings.Cut(url, "://")
+	if !found || scheme == "" {
+		return false
+	}
+	for i, r := range scheme {
+		isLetter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		if i == 0 && !isLetter || !isLetter && !(r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WithScheme replaces the scheme of the url or adds it if the url has no scheme.
+func WithScheme(url string, scheme string) string {
+	if HasScheme(url) {
+		_, url, _ = strings.Cut(url, "://")
+	}
 
-	return parsedUrl.Scheme + "://" + parsedUrl.Hostname(), nil
+	return scheme + "://" + strings.TrimPrefix(url, "//")
 }
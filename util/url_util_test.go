@@ -0,0 +1,108 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CanonicalizeUrl(t *testing.T) {
+	testSet := []struct {
+		name        string
+		url         string
+		expected    string
+		expectedErr bool
+	}{
+		{name: "already canonical", url: "https://example.com/test", expected: "https://example.com/test"},
+		{name: "uppercase host and scheme", url: "HTTPS://ExAmPle.COM/Test", expected: "https://example.com/Test"},
+		{name: "trailing dot in host", url: "https://example.com./test", expected: "https://example.com/test"},
+		{name: "default https port", url: "https://example.com:443/test", expected: "https://example.com/test"},
+		{name: "default http port", url: "http://example.com:80/test", expected: "http://example.com/test"},
+		{name: "non-default port is kept", url: "https://example.com:8443/test", expected: "https://example.com:8443/test"},
+		{name: "http port on https is kept", url: "https://example.com:80/", expected: "https://example.com:80/"},
+		{name: "idn host", url: "https://bücher.example/katalog", expected: "https://xn--bcher-kva.example/katalog"},
+		{name: "uppercase idn host", url: "https://BÜCHER.example/", expected: "https://xn--bcher-kva.example/"},
+		{name: "punycode host", url: "https://XN--BCHER-KVA.example/", expected: "https://xn--bcher-kva.example/"},
+		{name: "empty path", url: "https://example.com", expected: "https://example.com/"},
+		{name: "fragment is dropped", url: "https://example.com/test#part", expected: "https://example.com/test"},
+		{name: "encoded unreserved characters", url: "https://example.com/%7Euser/%61bc", expected: "https://example.com/~user/abc"},
+		{name: "lowercase hex digits", url: "https://example.com/a%2fb%3a", expected: "https://example.com/a%2Fb%3A"},
+		{name: "non-ascii path", url: "https://example.com/straße", expected: "https://example.com/stra%C3%9Fe"},
+		{name: "query is normalized", url: "https://example.com/?q=%7e%2f", expected: "https://example.com/?q=~%2F"},
+		{name: "invalid percent sequence", url: "https://example.com/?q=100%", expected: "https://example.com/?q=100%"},
+		{name: "ipv4 host", url: "http://127.0.0.1:80/test", expected: "http://127.0.0.1/test"},
+		{name: "ipv6 host", url: "http://[0:0:0:0:0:0:0:1]:8080/", expected: "http://[::1]:8080/"},
+		{name: "underscore in host", url: "https://my_host.example.com/", expected: "https://my_host.example.com/"},
+		{name: "missing scheme", url: "example.com/test", expectedErr: true},
+		{name: "missing host", url: "https:///test", expectedErr: true},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			actual, err := CanonicalizeUrl(test.url)
+			if test.expectedErr {
+				assert.Error(tt, err)
+				return
+			}
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.expected, actual)
+		})
+	}
+}
+
+func Test_GetDomain(t *testing.T) {
+	testSet := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{name: "lowercase", url: "https://WWW.Example.com/test", expected: "www.example.com"},
+		{name: "trailing dot", url: "https://example.com./", expected: "example.com"},
+		{name: "port is not a part of the domain", url: "https://example.com:8443/", expected: "example.com"},
+		{name: "idn", url: "https://пример.рф/", expected: "xn--e1afmkfd.xn--p1ai"},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			actual, err := GetDomain(test.url)
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.expected, actual)
+		})
+	}
+}
+
+func Test_GetBaseUrl(t *testing.T) {
+	testSet := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{name: "default port", url: "HTTPS://Example.com:443/test?q=1", expected: "https://example.com"},
+		{name: "custom port", url: "http://example.com:8080/test", expected: "http://example.com:8080"},
+		{name: "idn", url: "https://bücher.example./", expected: "https://xn--bcher-kva.example"},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			actual, err := GetBaseUrl(test.url)
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.expected, actual)
+		})
+	}
+}
+
+func Test_WithScheme(t *testing.T) {
+	testSet := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{name: "no scheme", url: "example.com/path", expected: "https://example.com/path"},
+		{name: "scheme-relative", url: "//example.com/path", expected: "https://example.com/path"},
+		{name: "port without scheme", url: "example.com:8080/path", expected: "https://example.com:8080/path"},
+		{name: "replace scheme", url: "http://example.com/path", expected: "https://example.com/path"},
+		{name: "scheme in query", url: "example.com/?next=http://other.com", expected: "https://example.com/?next=http://other.com"},
+	}
+	for _, test := range testSet {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, WithScheme(test.url, "https"))
+		})
+	}
+}